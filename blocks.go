@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+var errBlocked = errors.New("You cannot interact with this user")
+
+type RelatedUser struct {
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type relatedUserPage struct {
+	Users      []RelatedUser `json:"users"`
+	NextCursor *string       `json:"next_cursor"`
+}
+
+// isBlockedBy reports whether userID has been blocked by the author of a
+// chirp they are about to interact with.
+func isBlockedBy(ctx context.Context, q *database.Queries, authorID uuid.NullUUID, userID uuid.UUID) (bool, error) {
+	if !authorID.Valid {
+		return false, nil
+	}
+	return q.IsBlocked(ctx, database.IsBlockedParams{
+		BlockerID: authorID.UUID,
+		BlockedID: userID,
+	})
+}
+
+// relationshipTarget reads the user a block or mute request is about.
+func (cfg *apiConfig) relationshipTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if targetID == userID {
+		respondWithError(w, 400, "You cannot do this to yourself")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, targetID, true
+}
+
+// handlerBlockUser blocks a user and removes any follows between the two,
+// so neither keeps seeing the other in their timeline.
+func (cfg *apiConfig) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := cfg.relationshipTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.GetUser(r.Context(), targetID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		_, err := q.BlockUser(r.Context(), database.BlockUserParams{
+			BlockerID: userID,
+			BlockedID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+		return q.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{
+			FollowerID: userID,
+			FolloweeID: targetID,
+		})
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error blocking user")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := cfg.relationshipTarget(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := cfg.dbQueries.UnblockUser(r.Context(), database.UnblockUserParams{
+		BlockerID: userID,
+		BlockedID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error unblocking user")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, 404, "User is not blocked")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := cfg.relationshipTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.GetUser(r.Context(), targetID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	_, err = cfg.dbQueries.MuteUser(r.Context(), database.MuteUserParams{
+		MuterID: userID,
+		MutedID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error muting user")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := cfg.relationshipTarget(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := cfg.dbQueries.UnmuteUser(r.Context(), database.UnmuteUserParams{
+		MuterID: userID,
+		MutedID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error unmuting user")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, 404, "User is not muted")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListBlockedUsers(r.Context(), database.ListBlockedUsersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting blocked users")
+		return
+	}
+
+	rows, next := nextCursor(rows, limit, func(row database.ListBlockedUsersRow) (time.Time, uuid.UUID) {
+		return row.CreatedAt, row.UserID
+	})
+	users := make([]RelatedUser, len(rows))
+	for i, row := range rows {
+		users[i] = RelatedUser{UserID: row.UserID.String(), CreatedAt: row.CreatedAt.String()}
+	}
+
+	respondWithJSON(w, 200, relatedUserPage{Users: users, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerGetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListMutedUsers(r.Context(), database.ListMutedUsersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting muted users")
+		return
+	}
+
+	rows, next := nextCursor(rows, limit, func(row database.ListMutedUsersRow) (time.Time, uuid.UUID) {
+		return row.CreatedAt, row.UserID
+	})
+	users := make([]RelatedUser, len(rows))
+	for i, row := range rows {
+		users[i] = RelatedUser{UserID: row.UserID.String(), CreatedAt: row.CreatedAt.String()}
+	}
+
+	respondWithJSON(w, 200, relatedUserPage{Users: users, NextCursor: next})
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rivo/uniseg"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	maxCollectionNameLength = 50
+	maxBookmarkCollections  = 100
+)
+
+type BookmarkCollection struct {
+	ID            string `json:"id"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	Name          string `json:"name"`
+	BookmarkCount int64  `json:"bookmark_count"`
+}
+
+func formatBookmarkCollection(collection database.BookmarkCollection, count int64) BookmarkCollection {
+	return BookmarkCollection{
+		ID:            collection.ID.String(),
+		CreatedAt:     collection.CreatedAt.String(),
+		UpdatedAt:     collection.UpdatedAt.String(),
+		Name:          collection.Name,
+		BookmarkCount: count,
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func decodeCollectionName(r *http.Request) (string, error) {
+	type parameters struct {
+		Name string `json:"name"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		return "", errors.New("Error decoding JSON")
+	}
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return "", errors.New("Collection name is required")
+	}
+	if uniseg.GraphemeClusterCount(name) > maxCollectionNameLength {
+		return "", errors.New("Collection name is too long")
+	}
+	return name, nil
+}
+
+// getBookmarkedChirpIDs returns which of the chirps the viewer has saved to
+// any of their collections.
+func (cfg *apiConfig) getBookmarkedChirpIDs(ctx context.Context, viewerID uuid.NullUUID, chirpIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	if !viewerID.Valid {
+		return nil, nil
+	}
+	ids, err := cfg.dbQueries.GetBookmarkedChirpIDs(ctx, database.GetBookmarkedChirpIDsParams{
+		UserID:   viewerID.UUID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bookmarked := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+func (cfg *apiConfig) handlerGetBookmarkCollections(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListBookmarkCollections(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting bookmark collections")
+		return
+	}
+
+	collections := make([]BookmarkCollection, len(rows))
+	for i, row := range rows {
+		collections[i] = formatBookmarkCollection(database.BookmarkCollection{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			UserID:    row.UserID,
+			Name:      row.Name,
+		}, row.BookmarkCount)
+	}
+
+	respondWithJSON(w, 200, collections)
+}
+
+func (cfg *apiConfig) handlerCreateBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	name, err := decodeCollectionName(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	count, err := cfg.dbQueries.CountBookmarkCollections(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Error creating collection")
+		return
+	}
+	if count >= maxBookmarkCollections {
+		respondWithError(w, 400, "You have reached the maximum number of collections")
+		return
+	}
+
+	collection, err := cfg.dbQueries.CreateBookmarkCollection(r.Context(), database.CreateBookmarkCollectionParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 409, "You already have a collection with that name")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error creating collection")
+		return
+	}
+
+	respondWithJSON(w, 201, formatBookmarkCollection(collection, 0))
+}
+
+func (cfg *apiConfig) handlerRenameBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid collection ID")
+		return
+	}
+	name, err := decodeCollectionName(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	row, err := cfg.dbQueries.RenameBookmarkCollection(r.Context(), database.RenameBookmarkCollectionParams{
+		Name:   name,
+		ID:     id,
+		UserID: userID,
+	})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondWithError(w, 404, "Collection not found")
+		return
+	case isUniqueViolation(err):
+		respondWithError(w, 409, "You already have a collection with that name")
+		return
+	case err != nil:
+		respondWithError(w, 500, "Error renaming collection")
+		return
+	}
+
+	respondWithJSON(w, 200, formatBookmarkCollection(database.BookmarkCollection{
+		ID:        row.ID,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		UserID:    row.UserID,
+		Name:      row.Name,
+	}, row.BookmarkCount))
+}
+
+func (cfg *apiConfig) handlerDeleteBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid collection ID")
+		return
+	}
+
+	// Its bookmarks are removed with it
+	deleted, err := cfg.dbQueries.DeleteBookmarkCollection(r.Context(), database.DeleteBookmarkCollectionParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error deleting collection")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "Collection not found")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetBookmarkedChirps(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid collection ID")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	_, err = cfg.dbQueries.GetBookmarkCollection(r.Context(), database.GetBookmarkCollectionParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Collection not found")
+		return
+	}
+
+	// Most recently saved chirps come first
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListBookmarkedChirps(r.Context(), database.ListBookmarkedChirpsParams{
+		CollectionID:    id,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting bookmarks")
+		return
+	}
+	rows, next := nextCursor(rows, limit, func(row database.ListBookmarkedChirpsRow) (time.Time, uuid.UUID) {
+		return row.BookmarkedAt, row.Chirp.ID
+	})
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting bookmarks")
+		return
+	}
+
+	respondWithJSON(w, 200, chirpPage{Chirps: formattedChirps, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerAddBookmark(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		ChirpID uuid.UUID `json:"chirp_id"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid collection ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+
+	_, err = cfg.dbQueries.GetBookmarkCollection(r.Context(), database.GetBookmarkCollectionParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Collection not found")
+		return
+	}
+	_, err = cfg.dbQueries.GetChirp(r.Context(), params.ChirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	err = cfg.dbQueries.AddBookmark(r.Context(), database.AddBookmarkParams{
+		CollectionID: id,
+		ChirpID:      params.ChirpID,
+		UserID:       userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error saving bookmark")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerRemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid collection ID")
+		return
+	}
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	removed, err := cfg.dbQueries.RemoveBookmark(r.Context(), database.RemoveBookmarkParams{
+		CollectionID: id,
+		ChirpID:      chirpID,
+		UserID:       userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error removing bookmark")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, 404, "Bookmark not found")
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tiemouie01/chirpy/internal/auth"
@@ -11,33 +14,365 @@ import (
 )
 
 type Chirp struct {
-	ID        string `json:"id"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
-	Body      string `json:"body"`
-	UserID    string `json:"user_id"`
+	ID         string         `json:"id"`
+	CreatedAt  string         `json:"created_at"`
+	UpdatedAt  string         `json:"updated_at"`
+	Body       string         `json:"body"`
+	UserID     string         `json:"user_id"`
+	Author     *AuthorSummary `json:"author"`
+	ParentID   *string        `json:"parent_id"`
+	PublishAt  *string        `json:"publish_at,omitempty"`
+	Edited     bool           `json:"edited"`
+	Deleted    bool           `json:"deleted"`
+	LikeCount  int64          `json:"like_count"`
+	LikedByMe  bool           `json:"liked_by_me"`
+	Bookmarked bool           `json:"bookmarked"`
+	Pinned     bool           `json:"pinned"`
+
+	Mentions    []Mention    `json:"mentions"`
+	Attachments []Attachment `json:"attachments"`
+	Preview     *LinkPreview `json:"preview"`
+	Poll        *Poll        `json:"poll"`
+
+	RechirpOfID   *string `json:"rechirp_of_id"`
+	RechirpOf     *Chirp  `json:"rechirp_of"`
+	QuotedChirpID *string `json:"quoted_chirp_id"`
+	QuotedChirp   *Chirp  `json:"quoted_chirp"`
+}
+
+// Rechirps and quotes embed the chirp they reference. Embedded chirps only
+// carry the IDs of their own references so that chains stay shallow.
+const maxEmbedDepth = 1
+
+func formatChirp(chirp database.Chirp) Chirp {
+	formattedChirp := Chirp{
+		ID:        chirp.ID.String(),
+		CreatedAt: chirp.CreatedAt.String(),
+		UpdatedAt: chirp.UpdatedAt.String(),
+		Body:      chirp.Body,
+		UserID:    chirp.UserID.UUID.String(),
+		Edited:    chirp.EditedAt.Valid,
+		Mentions:  []Mention{},
+
+		Attachments: []Attachment{},
+	}
+	formattedChirp.ParentID = nullUUIDString(chirp.ParentID)
+	formattedChirp.RechirpOfID = nullUUIDString(chirp.RechirpOfID)
+	formattedChirp.QuotedChirpID = nullUUIDString(chirp.QuotedChirpID)
+	if chirp.ScheduledFor.Valid {
+		publishAt := chirp.ScheduledFor.Time.String()
+		formattedChirp.PublishAt = &publishAt
+	}
+
+	// Deleted chirps that still have replies are shown as tombstones so the
+	// thread stays intact, but their content and author are hidden.
+	if chirp.DeletedAt.Valid {
+		formattedChirp.Body = ""
+		formattedChirp.UserID = ""
+		formattedChirp.Deleted = true
+	}
+	return formattedChirp
+}
+
+func nullUUIDString(id uuid.NullUUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	idString := id.UUID.String()
+	return &idString
+}
+
+// formatChirps converts database chirps into API chirps, loading the
+// details that live in other tables with one query per detail for the
+// whole slice rather than one per chirp.
+func (cfg *apiConfig) formatChirps(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	return cfg.formatChirpsAtDepth(ctx, viewerID, chirps, 0)
+}
+
+func (cfg *apiConfig) formatChirpsAtDepth(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp, depth int) ([]Chirp, error) {
+	formattedChirps := make([]Chirp, len(chirps))
+	if len(chirps) == 0 {
+		return formattedChirps, nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	positions := make(map[uuid.UUID]int, len(chirps))
+	for i, chirp := range chirps {
+		formattedChirps[i] = formatChirp(chirp)
+		chirpIDs[i] = chirp.ID
+		positions[chirp.ID] = i
+	}
+
+	likeStats, err := cfg.dbQueries.GetChirpLikeStats(ctx, database.GetChirpLikeStatsParams{
+		ViewerID: viewerID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, stats := range likeStats {
+		chirp := &formattedChirps[positions[stats.ChirpID]]
+		chirp.LikeCount = stats.LikeCount
+		chirp.LikedByMe = stats.LikedByMe
+	}
+
+	bookmarked, err := cfg.getBookmarkedChirpIDs(ctx, viewerID, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id := range bookmarked {
+		formattedChirps[positions[id]].Bookmarked = true
+	}
+
+	mentions, err := cfg.dbQueries.GetMentionsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, mention := range mentions {
+		chirp := &formattedChirps[positions[mention.ChirpID]]
+		if chirp.Deleted {
+			continue
+		}
+		chirp.Mentions = append(chirp.Mentions, Mention{
+			UserID: mention.UserID,
+			Start:  mention.StartOffset,
+			End:    mention.EndOffset,
+		})
+	}
+
+	attachments, err := cfg.dbQueries.GetMediaForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		chirp := &formattedChirps[positions[attachment.ChirpID.UUID]]
+		if chirp.Deleted {
+			continue
+		}
+		chirp.Attachments = append(chirp.Attachments, cfg.formatAttachment(attachment))
+	}
+
+	// Only the first link in a chirp is unfurled
+	links := make([]string, len(chirps))
+	for i, chirp := range formattedChirps {
+		links[i] = firstURL(chirp.Body)
+	}
+	previews, err := cfg.dbQueries.GetLinkPreviews(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	previewsByURL := make(map[string]*LinkPreview, len(previews))
+	for _, preview := range previews {
+		previewsByURL[preview.Url] = &LinkPreview{
+			URL:         preview.Url,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageUrl,
+			SiteName:    preview.SiteName,
+		}
+	}
+	for i, link := range links {
+		if link != "" {
+			formattedChirps[i].Preview = previewsByURL[link]
+		}
+	}
+
+	authors, err := cfg.getAuthorSummaries(ctx, chirps)
+	if err != nil {
+		return nil, err
+	}
+	for i, chirp := range chirps {
+		if !formattedChirps[i].Deleted && chirp.UserID.Valid {
+			formattedChirps[i].Author = authors[chirp.UserID.UUID]
+		}
+	}
+
+	polls, err := cfg.getPolls(ctx, viewerID, chirps)
+	if err != nil {
+		return nil, err
+	}
+	for i, chirp := range chirps {
+		if !formattedChirps[i].Deleted {
+			formattedChirps[i].Poll = polls[chirp.ID]
+		}
+	}
+
+	if depth < maxEmbedDepth {
+		err = cfg.embedReferencedChirps(ctx, viewerID, chirps, formattedChirps, depth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return formattedChirps, nil
+}
+
+func (cfg *apiConfig) embedReferencedChirps(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp, formattedChirps []Chirp, depth int) error {
+	referencedIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RechirpOfID.Valid {
+			referencedIDs = append(referencedIDs, chirp.RechirpOfID.UUID)
+		}
+		if chirp.QuotedChirpID.Valid {
+			referencedIDs = append(referencedIDs, chirp.QuotedChirpID.UUID)
+		}
+	}
+	if len(referencedIDs) == 0 {
+		return nil
+	}
+
+	referenced, err := cfg.dbQueries.GetChirpsByIDs(ctx, referencedIDs)
+	if err != nil {
+		return err
+	}
+	formattedReferenced, err := cfg.formatChirpsAtDepth(ctx, viewerID, referenced, depth+1)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*Chirp, len(referenced))
+	for i, chirp := range referenced {
+		byID[chirp.ID] = &formattedReferenced[i]
+	}
+
+	for i, chirp := range chirps {
+		if chirp.RechirpOfID.Valid {
+			formattedChirps[i].RechirpOf = byID[chirp.RechirpOfID.UUID]
+		}
+		if chirp.QuotedChirpID.Valid {
+			formattedChirps[i].QuotedChirp = byID[chirp.QuotedChirpID.UUID]
+		}
+	}
+	return nil
+}
+
+var (
+	errParentNotFound      = errors.New("Parent chirp not found")
+	errQuotedChirpNotFound = errors.New("Quoted chirp not found")
+)
+
+// newChirp is a validated chirp that is ready to be stored by insertChirp.
+type newChirp struct {
+	params   database.CreateChirpParams
+	mediaIDs []uuid.UUID
+	poll     *newPoll
+	// Blocked words left in the body for moderators to review
+	flagged []string
 }
 
-func cleanChirp(body string) string {
-	profaneWords := []string{"kerfuffle", "sharbert", "fornax"}
-	words := strings.Split(body, " ")
-	for i, word := range words {
-		lowerWord := strings.ToLower(word)
-		for _, profaneWord := range profaneWords {
-			if lowerWord == profaneWord {
-				words[i] = "****"
-			}
+// prepareChirp validates a chirp the user is about to post and cleans its
+// body. Replies must point at a chirp that still exists and whose author has
+// not blocked the user, and quotes always reference the original chirp
+// rather than a rechirp of it.
+func (cfg *apiConfig) prepareChirp(ctx context.Context, q *database.Queries, userID uuid.UUID, body string, parentID, quotedChirpID *uuid.UUID) (newChirp, error) {
+	limit, err := cfg.chirpLengthLimit(ctx, q, userID)
+	if err != nil {
+		return newChirp{}, err
+	}
+	err = validateChirpBody(body, limit)
+	if err != nil {
+		return newChirp{}, err
+	}
+	cleanedBody, flagged, err := cfg.cleanChirp(body)
+	if err != nil {
+		return newChirp{}, err
+	}
+
+	chirp := newChirp{
+		params: database.CreateChirpParams{
+			Body:   cleanedBody,
+			UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		},
+		flagged: flagged,
+	}
+	if parentID != nil {
+		parent, err := q.GetChirp(ctx, *parentID)
+		if err != nil {
+			return newChirp{}, errParentNotFound
+		}
+		blocked, err := isBlockedBy(ctx, q, parent.UserID, userID)
+		if err != nil {
+			return newChirp{}, err
 		}
+		if blocked {
+			return newChirp{}, errBlocked
+		}
+		chirp.params.ParentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	}
+	if quotedChirpID != nil {
+		quoted, err := getRechirpableChirp(ctx, q, *quotedChirpID)
+		if err != nil {
+			return newChirp{}, errQuotedChirpNotFound
+		}
+		chirp.params.QuotedChirpID = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	}
+	return chirp, nil
+}
+
+// insertChirp stores a new chirp together with its attachments, poll, any
+// profanity flag and the hashtags and mentions found in its body, and
+// returns the notifications it created. Callers run
+// it inside a transaction so everything is written together, and publish
+// the notifications once it commits.
+func insertChirp(ctx context.Context, q *database.Queries, prepared newChirp) (database.Chirp, []database.Notification, error) {
+	chirp, err := q.CreateChirp(ctx, prepared.params)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	err = attachMedia(ctx, q, chirp, prepared.mediaIDs)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	err = createPoll(ctx, q, chirp.ID, prepared.poll)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	err = flagChirp(ctx, q, chirp.ID, prepared.flagged)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	// Scheduled chirps are indexed when the scheduler publishes them
+	if chirp.ScheduledFor.Valid {
+		return chirp, nil, nil
+	}
+	created, err := indexChirp(ctx, q, chirp)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	return chirp, created, nil
+}
+
+// indexChirp records the hashtags and mentions of a chirp that has just
+// become visible and notifies the users it concerns.
+func indexChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) ([]database.Notification, error) {
+	err := tagChirp(ctx, q, chirp)
+	if err != nil {
+		return nil, err
+	}
+	created, err := mentionUsers(ctx, q, chirp, nil)
+	if err != nil {
+		return nil, err
 	}
-	return strings.Join(words, " ")
+	notification, err := notifyReply(ctx, q, chirp)
+	if err != nil {
+		return nil, err
+	}
+	if notification != nil {
+		created = append(created, *notification)
+	}
+	return created, nil
 }
+
 func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Body string `json:"body"`
+		Body          string          `json:"body"`
+		ParentID      *uuid.UUID      `json:"parent_id"`
+		QuotedChirpID *uuid.UUID      `json:"quoted_chirp_id"`
+		PublishAt     *time.Time      `json:"publish_at"`
+		MediaIDs      []uuid.UUID     `json:"media_ids"`
+		Poll          *pollParameters `json:"poll"`
 	}
 
-	const characterError = "Chirp is too long"
-
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
 	err := decoder.Decode(&params)
@@ -46,11 +381,6 @@ func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(w, 400, characterError)
-		return
-	}
-
 	// Get user ID from JWT token
 	token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -63,28 +393,96 @@ func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Clean chirp body
-	cleanedBody := cleanChirp(params.Body)
+	prepared, err := cfg.prepareChirp(r.Context(), cfg.dbQueries, userID, params.Body, params.ParentID, params.QuotedChirpID)
+	if err != nil {
+		respondWithInvalidChirp(w, err, "Error creating chirp")
+		return
+	}
+
+	err = validateMediaIDs(params.MediaIDs)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	prepared.mediaIDs = params.MediaIDs
+
+	// Chirps with a publish time stay hidden until the scheduler releases them
+	if params.PublishAt != nil {
+		if !params.PublishAt.After(time.Now()) {
+			respondWithError(w, 400, "publish_at must be in the future")
+			return
+		}
+		prepared.params.ScheduledFor = sql.NullTime{Time: params.PublishAt.UTC(), Valid: true}
+	}
+
+	if params.Poll != nil {
+		opensAt := time.Now()
+		if params.PublishAt != nil {
+			opensAt = *params.PublishAt
+		}
+		poll, flagged, err := cfg.preparePoll(*params.Poll, opensAt)
+		if err != nil {
+			respondWithError(w, 400, err.Error())
+			return
+		}
+		prepared.poll = poll
+		prepared.flagged = append(prepared.flagged, flagged...)
+	}
 
-	createChirpParams := database.CreateChirpParams{
-		Body:   cleanedBody,
-		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	var chirp database.Chirp
+	var created []database.Notification
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		chirp, created, err = insertChirp(r.Context(), q, prepared)
+		return err
+	})
+	if errors.Is(err, errMediaNotFound) {
+		respondWithError(w, 400, err.Error())
+		return
 	}
-	chirp, err := cfg.dbQueries.CreateChirp(r.Context(), createChirpParams)
 	if err != nil {
 		respondWithError(w, 500, "Error creating chirp")
 		return
 	}
+	for _, notification := range created {
+		cfg.publishNotification(&notification)
+	}
 
-	formattedChirp := Chirp{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.String(),
-		UpdatedAt: chirp.UpdatedAt.String(),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.UUID.String(),
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error creating chirp")
+		return
 	}
 
-	respondWithJSON(w, 200, formattedChirp)
+	if chirp.ScheduledFor.Valid {
+		cfg.wakeScheduler()
+		respondWithJSON(w, 202, formattedChirps[0])
+		return
+	}
+
+	cfg.publishChirpCreated(r.Context(), chirp)
+	cfg.queueLinkPreview(chirp.Body)
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
+
+type chirpPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func (cfg *apiConfig) newChirpPage(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp, limit int32) (chirpPage, error) {
+	chirps, next := nextCursor(chirps, limit, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	formattedChirps, err := cfg.formatChirps(ctx, viewerID, chirps)
+	if err != nil {
+		return chirpPage{}, err
+	}
+	return chirpPage{
+		Chirps:     formattedChirps,
+		NextCursor: next,
+	}, nil
 }
 
 func (cfg *apiConfig) handlerGetAllChirps(w http.ResponseWriter, r *http.Request) {
@@ -92,65 +490,112 @@ func (cfg *apiConfig) handlerGetAllChirps(w http.ResponseWriter, r *http.Request
 	authorID := r.URL.Query().Get("author_id")
 	sortParam := r.URL.Query().Get("sort")
 
-	var chirps []database.Chirp
-	var err error
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
 
+	authorUUID := uuid.NullUUID{}
 	if authorID != "" {
 		// Parse UUID from string
-		authorUUID, err := uuid.Parse(authorID)
+		parsed, err := uuid.Parse(authorID)
 		if err != nil {
 			respondWithError(w, 400, "Invalid authorID format")
 			return
 		}
+		authorUUID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
 
-		if sortParam == "desc" {
-			chirps, err = cfg.dbQueries.GetAllChirpsByAuthorDesc(r.Context(), uuid.NullUUID{
-				UUID:  authorUUID,
-				Valid: true,
-			})
-			if err != nil {
-				respondWithError(w, 500, "Error collecting author chirps")
-				return
-			}
-		} else {
-			chirps, err = cfg.dbQueries.GetAllChirpsByAuthor(r.Context(), uuid.NullUUID{
-				UUID:  authorUUID,
-				Valid: true,
-			})
-			if err != nil {
-				respondWithError(w, 500, "Error collecting author chirps")
-				return
-			}
+	// An author's pinned chirp leads their first page instead of appearing
+	// in its usual place
+	var pinned *database.Chirp
+	if authorUUID.Valid {
+		chirp, err := cfg.dbQueries.GetPinnedChirp(r.Context(), database.GetPinnedChirpParams{
+			UserID:   authorUUID.UUID,
+			ViewerID: viewerID,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 500, "Error collecting chirps")
+			return
 		}
-	} else {
-		if sortParam == "desc" {
-			chirps, err = cfg.dbQueries.GetAllChirpsDesc(r.Context())
-			if err != nil {
-				respondWithError(w, 500, "Error collecting chirps")
-				return
-			}
-		} else {
-			chirps, err = cfg.dbQueries.GetAllChirps(r.Context())
+		if err == nil {
+			pinned = &chirp
 		}
 	}
+	excludeID := uuid.NullUUID{}
+	if pinned != nil {
+		excludeID = uuid.NullUUID{UUID: pinned.ID, Valid: true}
+	}
+	// The pinned chirp takes one of the first page's slots
+	showPinned := pinned != nil && cursor == nil
+	rowLimit := limit
+	if showPinned {
+		rowLimit--
+	}
+
+	// Fetch one extra row to find out whether another page exists
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	listParams := database.ListChirpsParams{
+		ViewerID:        viewerID,
+		AuthorID:        authorUUID,
+		ExcludeID:       excludeID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       rowLimit + 1,
+	}
 
+	var chirps []database.Chirp
+	if sortParam == "desc" {
+		chirps, err = cfg.dbQueries.ListChirpsDesc(r.Context(), database.ListChirpsDescParams(listParams))
+	} else {
+		chirps, err = cfg.dbQueries.ListChirps(r.Context(), listParams)
+	}
 	if err != nil {
 		respondWithError(w, 500, "Error collecting chirps")
 		return
 	}
 
-	formattedChirps := make([]Chirp, len(chirps))
-	for i, chirp := range chirps {
-		formattedChirps[i] = Chirp{
-			ID:        chirp.ID.String(),
-			CreatedAt: chirp.CreatedAt.String(),
-			UpdatedAt: chirp.UpdatedAt.String(),
-			Body:      chirp.Body,
-			UserID:    chirp.UserID.UUID.String(),
+	page := chirpPage{Chirps: []Chirp{}}
+	if rowLimit > 0 {
+		page, err = cfg.newChirpPage(r.Context(), viewerID, chirps, rowLimit)
+		if err != nil {
+			respondWithError(w, 500, "Error collecting chirps")
+			return
 		}
+	} else if len(chirps) > 0 {
+		// Only the pinned chirp fits, so the next page starts at the
+		// beginning of the list
+		next := listStartCursor(sortParam == "desc")
+		page.NextCursor = &next
 	}
 
-	respondWithJSON(w, 200, formattedChirps)
+	if showPinned {
+		formattedPinned, err := cfg.formatChirps(r.Context(), viewerID, []database.Chirp{*pinned})
+		if err != nil {
+			respondWithError(w, 500, "Error collecting chirps")
+			return
+		}
+		formattedPinned[0].Pinned = true
+		page.Chirps = append(formattedPinned, page.Chirps...)
+	}
+
+	respondWithJSON(w, 200, page)
+}
+
+// listStartCursor sorts before every chirp in the requested order, so a page
+// requested with it starts at the first chirp.
+func listStartCursor(desc bool) string {
+	if desc {
+		return encodeCursor(time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), uuid.Max)
+	}
+	return encodeCursor(time.Time{}, uuid.Nil)
 }
 
 func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
@@ -160,21 +605,25 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
 	chirp, err := cfg.dbQueries.GetChirp(r.Context(), id)
 	if err != nil {
 		respondWithError(w, 404, "Chirp not found.")
 		return
 	}
 
-	formattedChirp := Chirp{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.String(),
-		UpdatedAt: chirp.UpdatedAt.String(),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.UUID.String(),
+	formattedChirps, err := cfg.formatChirps(r.Context(), viewerID, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting chirp")
+		return
 	}
 
-	respondWithJSON(w, 200, formattedChirp)
+	respondWithJSON(w, 200, formattedChirps[0])
 }
 
 func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
@@ -198,8 +647,8 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Delete the chirp from the database
-	err = cfg.dbQueries.DeleteChirp(r.Context(), database.DeleteChirpParams{
+	// Deleted chirps stay restorable until the purge job removes them
+	deleted, err := cfg.dbQueries.SoftDeleteChirp(r.Context(), database.SoftDeleteChirpParams{
 		ID: id,
 		UserID: uuid.NullUUID{
 			UUID:  userId,
@@ -207,9 +656,12 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		},
 	})
 	if err != nil {
-		respondWithError(w, 404, "Chirp not found")
+		respondWithError(w, 500, "Error deleting chirp")
 		return
 	}
+	if deleted > 0 {
+		cfg.publishChirpDeleted(id, userId)
+	}
 
 	w.WriteHeader(204)
 }
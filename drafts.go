@@ -0,0 +1,283 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+// Drafts may run past the chirp limit while they are being written, but are
+// still capped so they cannot be used as free storage.
+const maxDraftLength = 4096
+
+type Draft struct {
+	ID            string  `json:"id"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+	Body          string  `json:"body"`
+	ParentID      *string `json:"parent_id"`
+	QuotedChirpID *string `json:"quoted_chirp_id"`
+}
+
+type draftPage struct {
+	Drafts     []Draft `json:"drafts"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+type draftParameters struct {
+	Body          string     `json:"body"`
+	ParentID      *uuid.UUID `json:"parent_id"`
+	QuotedChirpID *uuid.UUID `json:"quoted_chirp_id"`
+}
+
+func formatDraft(draft database.Draft) Draft {
+	return Draft{
+		ID:            draft.ID.String(),
+		CreatedAt:     draft.CreatedAt.String(),
+		UpdatedAt:     draft.UpdatedAt.String(),
+		Body:          draft.Body,
+		ParentID:      nullUUIDString(draft.ParentID),
+		QuotedChirpID: nullUUIDString(draft.QuotedChirpID),
+	}
+}
+
+func optionalUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func nullUUIDPointer(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func decodeDraftParameters(r *http.Request) (draftParameters, error) {
+	decoder := json.NewDecoder(r.Body)
+	params := draftParameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		return draftParameters{}, errors.New("Error decoding JSON")
+	}
+	if len(params.Body) > maxDraftLength {
+		return draftParameters{}, errors.New("Draft is too long")
+	}
+	return params, nil
+}
+
+func (cfg *apiConfig) handlerCreateDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	params, err := decodeDraftParameters(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	draft, err := cfg.dbQueries.CreateDraft(r.Context(), database.CreateDraftParams{
+		UserID:        userID,
+		Body:          params.Body,
+		ParentID:      optionalUUID(params.ParentID),
+		QuotedChirpID: optionalUUID(params.QuotedChirpID),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error creating draft")
+		return
+	}
+
+	respondWithJSON(w, 201, formatDraft(draft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	// Recently edited drafts come first
+	cursorUpdatedAt, cursorID := cursorParams(cursor)
+	drafts, err := cfg.dbQueries.ListDrafts(r.Context(), database.ListDraftsParams{
+		UserID:          userID,
+		CursorUpdatedAt: cursorUpdatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting drafts")
+		return
+	}
+	drafts, next := nextCursor(drafts, limit, func(draft database.Draft) (time.Time, uuid.UUID) {
+		return draft.UpdatedAt, draft.ID
+	})
+
+	formattedDrafts := make([]Draft, len(drafts))
+	for i, draft := range drafts {
+		formattedDrafts[i] = formatDraft(draft)
+	}
+
+	respondWithJSON(w, 200, draftPage{Drafts: formattedDrafts, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerGetDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid draft ID")
+		return
+	}
+
+	draft, err := cfg.dbQueries.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Draft not found")
+		return
+	}
+
+	respondWithJSON(w, 200, formatDraft(draft))
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid draft ID")
+		return
+	}
+
+	params, err := decodeDraftParameters(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	draft, err := cfg.dbQueries.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		Body:          params.Body,
+		ParentID:      optionalUUID(params.ParentID),
+		QuotedChirpID: optionalUUID(params.QuotedChirpID),
+		ID:            id,
+		UserID:        userID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Draft not found")
+		return
+	}
+
+	respondWithJSON(w, 200, formatDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid draft ID")
+		return
+	}
+
+	_, err = cfg.dbQueries.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Draft not found")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// handlerPublishDraft turns a draft into a chirp. The draft is removed in the
+// same transaction that creates the chirp, so it is published exactly once.
+func (cfg *apiConfig) handlerPublishDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid draft ID")
+		return
+	}
+
+	var chirp database.Chirp
+	var created []database.Notification
+	var rejected error
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		draft, err := q.DeleteDraft(r.Context(), database.DeleteDraftParams{
+			ID:     id,
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		prepared, err := cfg.prepareChirp(r.Context(), q, userID, draft.Body, nullUUIDPointer(draft.ParentID), nullUUIDPointer(draft.QuotedChirpID))
+		if err != nil {
+			rejected = err
+			return err
+		}
+		chirp, created, err = insertChirp(r.Context(), q, prepared)
+		return err
+	})
+	switch {
+	case rejected != nil:
+		respondWithInvalidChirp(w, rejected, "Error publishing draft")
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		respondWithError(w, 404, "Draft not found")
+		return
+	case err != nil:
+		respondWithError(w, 500, "Error publishing draft")
+		return
+	}
+	for _, notification := range created {
+		cfg.publishNotification(&notification)
+	}
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft")
+		return
+	}
+
+	cfg.publishChirpCreated(r.Context(), chirp)
+	cfg.queueLinkPreview(chirp.Body)
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
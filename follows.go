@@ -0,0 +1,208 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/notifications"
+)
+
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followPage struct {
+	Users      []Follow `json:"users"`
+	NextCursor *string  `json:"next_cursor"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	followerID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid user ID")
+		return
+	}
+	if followeeID == followerID {
+		respondWithError(w, 400, "You cannot follow yourself")
+		return
+	}
+
+	// Make sure the user being followed exists
+	_, err = cfg.dbQueries.GetUser(r.Context(), followeeID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	blocked, err := isBlockedBy(r.Context(), cfg.dbQueries, uuid.NullUUID{UUID: followeeID, Valid: true}, followerID)
+	if err != nil {
+		respondWithError(w, 500, "Error following user")
+		return
+	}
+	if blocked {
+		respondWithError(w, 403, errBlocked.Error())
+		return
+	}
+
+	var notification *database.Notification
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		followed, err := q.FollowUser(r.Context(), database.FollowUserParams{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		})
+		if err != nil || followed == 0 {
+			return err
+		}
+		notification, err = notifications.Record(r.Context(), q, notifications.Event{
+			Type:    notifications.TypeFollow,
+			UserID:  followeeID,
+			ActorID: uuid.NullUUID{UUID: followerID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error following user")
+		return
+	}
+	cfg.publishNotification(notification)
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	followerID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid user ID")
+		return
+	}
+
+	err = cfg.dbQueries.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error unfollowing user")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid user ID")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListFollowers(r.Context(), database.ListFollowersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting followers")
+		return
+	}
+
+	rows, next := nextCursor(rows, limit, func(row database.ListFollowersRow) (time.Time, uuid.UUID) {
+		return row.CreatedAt, row.UserID
+	})
+	follows := make([]Follow, len(rows))
+	for i, row := range rows {
+		follows[i] = Follow{UserID: row.UserID, FollowedAt: row.CreatedAt}
+	}
+
+	respondWithJSON(w, 200, followPage{Users: follows, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid user ID")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListFollowing(r.Context(), database.ListFollowingParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting followed users")
+		return
+	}
+
+	rows, next := nextCursor(rows, limit, func(row database.ListFollowingRow) (time.Time, uuid.UUID) {
+		return row.CreatedAt, row.UserID
+	})
+	follows := make([]Follow, len(rows))
+	for i, row := range rows {
+		follows[i] = Follow{UserID: row.UserID, FollowedAt: row.CreatedAt}
+	}
+
+	respondWithJSON(w, 200, followPage{Users: follows, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerGetTimeline(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	// Timelines are always newest first
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	chirps, err := cfg.dbQueries.ListTimelineChirps(r.Context(), database.ListTimelineChirpsParams{
+		FollowerID:      userID,
+		ViewerID:        uuid.NullUUID{UUID: userID, Valid: true},
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting timeline")
+		return
+	}
+
+	page, err := cfg.newChirpPage(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps, limit)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting timeline")
+		return
+	}
+
+	respondWithJSON(w, 200, page)
+}
@@ -10,4 +10,12 @@ require github.com/joho/godotenv v1.5.1
 
 require golang.org/x/crypto v0.28.0
 
-require github.com/golang-jwt/jwt/v4 v4.5.1 
+require github.com/golang-jwt/jwt/v4 v4.5.1
+
+require github.com/gorilla/websocket v1.5.3
+
+require golang.org/x/net v0.25.0
+
+require golang.org/x/text v0.19.0
+
+require github.com/rivo/uniseg v0.4.7
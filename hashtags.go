@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	maxHashtagLength     = 100
+	defaultTrendingLimit = 10
+	maxTrendingLimit     = 50
+	defaultTrendWindow   = 24 * time.Hour
+	maxTrendWindow       = 7 * 24 * time.Hour
+)
+
+// A hashtag starts with # at the beginning of the body or after a character
+// that cannot be part of a word, URL path or HTML entity.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_]+)`)
+
+type TrendingHashtag struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+func normalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+// extractHashtags returns the distinct normalized hashtags in a chirp body.
+// Purely numeric tags such as #1 are ignored.
+func extractHashtags(body string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tag := normalizeHashtag(match[1])
+		if seen[tag] || len(tag) > maxHashtagLength || strings.Trim(tag, "0123456789") == "" {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func tagChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	for _, tag := range extractHashtags(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		err = q.AddChirpHashtag(ctx, database.AddChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := normalizeHashtag(r.PathValue("tag"))
+	if tag == "" {
+		respondWithError(w, 400, "Invalid hashtag")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	chirps, err := cfg.dbQueries.ListChirpsByHashtag(r.Context(), database.ListChirpsByHashtagParams{
+		Tag:             tag,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting chirps")
+		return
+	}
+
+	page, err := cfg.newChirpPage(r.Context(), viewerID, chirps, limit)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting chirps")
+		return
+	}
+
+	respondWithJSON(w, 200, page)
+}
+
+func (cfg *apiConfig) handlerGetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	// The window is a Go duration such as 1h or 30m
+	window := defaultTrendWindow
+	if windowParam := r.URL.Query().Get("window"); windowParam != "" {
+		parsed, err := time.ParseDuration(windowParam)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, 400, "window must be a positive duration such as 1h")
+			return
+		}
+		window = min(parsed, maxTrendWindow)
+	}
+
+	limit := defaultTrendingLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 {
+			respondWithError(w, 400, "limit must be a positive integer")
+			return
+		}
+		limit = min(parsed, maxTrendingLimit)
+	}
+
+	rows, err := cfg.dbQueries.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		WindowSeconds: int32(window.Seconds()),
+		TagLimit:      int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting trending hashtags")
+		return
+	}
+
+	trending := make([]TrendingHashtag, len(rows))
+	for i, row := range rows {
+		trending[i] = TrendingHashtag{Tag: row.Tag, ChirpCount: row.ChirpCount}
+	}
+
+	respondWithJSON(w, 200, trending)
+}
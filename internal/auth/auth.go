@@ -58,30 +58,48 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 }
 
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	userID, _, err := parseJWT(tokenString, tokenSecret)
+	return userID, err
+}
+
+// ValidateJWTWithExpiry validates a token like ValidateJWT and also returns
+// when it expires, for connections that outlive a single request. Such a
+// connection needs a deadline, so tokens without an expiry are rejected.
+func ValidateJWTWithExpiry(tokenString, tokenSecret string) (uuid.UUID, time.Time, error) {
+	userID, claims, err := parseJWT(tokenString, tokenSecret)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	if claims.ExpiresAt == nil {
+		return uuid.Nil, time.Time{}, errors.New("token has no expiry")
+	}
+	return userID, claims.ExpiresAt.Time, nil
+}
+
+func parseJWT(tokenString, tokenSecret string) (uuid.UUID, *jwt.RegisteredClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(tokenSecret), nil
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, nil, err
 	}
 
 	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
 		userID, err := uuid.Parse(claims.Subject)
 		if err != nil {
-			return uuid.Nil, errors.New("invalid user ID in token")
+			return uuid.Nil, nil, errors.New("invalid user ID in token")
 		}
-		return userID, nil
+		return userID, claims, nil
 	}
 
-	return uuid.Nil, errors.New("invalid token")
+	return uuid.Nil, nil, errors.New("invalid token")
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
 	reqToken := headers.Get("Authorization")
-	splitToken := strings.Split(reqToken, "Bearer ")
-	reqToken = splitToken[1]
+	reqToken, found := strings.CutPrefix(reqToken, "Bearer ")
 
-	if reqToken == "" {
+	if !found || reqToken == "" {
 		return "", errors.New("token does not exist")
 	}
 	return reqToken, nil
@@ -0,0 +1,135 @@
+package broker
+
+import "sync"
+
+// Message is a single event published to a topic. ID is optional and lets
+// streaming clients resume after reconnecting. Key is shared by the copies of
+// one event published to several topics, so a subscriber listening on more
+// than one of them can drop the duplicates.
+type Message struct {
+	Topic string
+	Event string
+	ID    string
+	Key   string
+	Data  any
+}
+
+// Broker fans messages out to every subscription listening on their topic.
+// Publishing never blocks: a subscriber whose buffer is full is dropped and
+// its channel closed so a slow client cannot hold up everyone else.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+type Subscription struct {
+	broker *Broker
+	topics map[string]struct{}
+	ch     chan Message
+	closed bool
+}
+
+func New() *Broker {
+	return &Broker{topics: map[string]map[*Subscription]struct{}{}}
+}
+
+// Subscribe registers a subscription with room for buffer pending messages.
+// If the broker is already closed the returned subscription's channel is
+// closed.
+func (b *Broker) Subscribe(buffer int, topics ...string) *Subscription {
+	sub := &Subscription{
+		broker: b,
+		topics: map[string]struct{}{},
+		ch:     make(chan Message, buffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.closed = true
+		close(sub.ch)
+		return sub
+	}
+	for _, topic := range topics {
+		b.addLocked(sub, topic)
+	}
+	return sub
+}
+
+func (b *Broker) Publish(msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.topics[msg.Topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+			b.dropLocked(sub)
+		}
+	}
+}
+
+// Close ends every subscription. Later publishes are ignored.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subs := range b.topics {
+		for sub := range subs {
+			b.dropLocked(sub)
+		}
+	}
+}
+
+func (b *Broker) addLocked(sub *Subscription, topic string) {
+	if b.topics[topic] == nil {
+		b.topics[topic] = map[*Subscription]struct{}{}
+	}
+	b.topics[topic][sub] = struct{}{}
+	sub.topics[topic] = struct{}{}
+}
+
+func (b *Broker) removeLocked(sub *Subscription, topic string) {
+	delete(b.topics[topic], sub)
+	if len(b.topics[topic]) == 0 {
+		delete(b.topics, topic)
+	}
+	delete(sub.topics, topic)
+}
+
+func (b *Broker) dropLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	for topic := range sub.topics {
+		b.removeLocked(sub, topic)
+	}
+	sub.closed = true
+	close(sub.ch)
+}
+
+// Messages is closed when the subscription is dropped, closed, or the
+// broker shuts down.
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+func (s *Subscription) Add(topic string) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	if !s.closed {
+		s.broker.addLocked(s, topic)
+	}
+}
+
+func (s *Subscription) Remove(topic string) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.removeLocked(s, topic)
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.dropLocked(s)
+}
@@ -0,0 +1,338 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: bookmarks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const addBookmark = `-- name: AddBookmark :exec
+INSERT INTO bookmarks (collection_id, chirp_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT DO NOTHING
+`
+
+type AddBookmarkParams struct {
+	CollectionID uuid.UUID
+	ChirpID      uuid.UUID
+	UserID       uuid.UUID
+}
+
+func (q *Queries) AddBookmark(ctx context.Context, arg AddBookmarkParams) error {
+	_, err := q.db.ExecContext(ctx, addBookmark, arg.CollectionID, arg.ChirpID, arg.UserID)
+	return err
+}
+
+const countBookmarkCollections = `-- name: CountBookmarkCollections :one
+SELECT COUNT(*) FROM bookmark_collections
+WHERE user_id = $1
+`
+
+func (q *Queries) CountBookmarkCollections(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBookmarkCollections, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBookmarkCollection = `-- name: CreateBookmarkCollection :one
+INSERT INTO bookmark_collections (id, created_at, updated_at, user_id, name)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING
+RETURNING id, created_at, updated_at, user_id, name
+`
+
+type CreateBookmarkCollectionParams struct {
+	UserID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) CreateBookmarkCollection(ctx context.Context, arg CreateBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRowContext(ctx, createBookmarkCollection, arg.UserID, arg.Name)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const deleteBookmarkCollection = `-- name: DeleteBookmarkCollection :execrows
+DELETE FROM bookmark_collections
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteBookmarkCollectionParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteBookmarkCollection(ctx context.Context, arg DeleteBookmarkCollectionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBookmarkCollection, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBookmarkCollection = `-- name: GetBookmarkCollection :one
+SELECT id, created_at, updated_at, user_id, name FROM bookmark_collections
+WHERE id = $1 AND user_id = $2
+`
+
+type GetBookmarkCollectionParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetBookmarkCollection(ctx context.Context, arg GetBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRowContext(ctx, getBookmarkCollection, arg.ID, arg.UserID)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const getBookmarkedChirpIDs = `-- name: GetBookmarkedChirpIDs :many
+SELECT DISTINCT chirp_id FROM bookmarks
+WHERE user_id = $1
+  AND chirp_id = ANY($2::uuid[])
+`
+
+type GetBookmarkedChirpIDsParams struct {
+	UserID   uuid.UUID
+	ChirpIds []uuid.UUID
+}
+
+func (q *Queries) GetBookmarkedChirpIDs(ctx context.Context, arg GetBookmarkedChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getBookmarkedChirpIDs, arg.UserID, pq.Array(arg.ChirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var chirp_id uuid.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookmarkCollections = `-- name: ListBookmarkCollections :many
+SELECT
+    bookmark_collections.id, bookmark_collections.created_at, bookmark_collections.updated_at, bookmark_collections.user_id, bookmark_collections.name,
+    COUNT(chirps.id) AS bookmark_count
+FROM bookmark_collections
+LEFT JOIN bookmarks ON bookmarks.collection_id = bookmark_collections.id
+LEFT JOIN chirps ON chirps.id = bookmarks.chirp_id AND chirps.deleted_at IS NULL
+WHERE bookmark_collections.user_id = $1
+GROUP BY bookmark_collections.id
+ORDER BY LOWER(bookmark_collections.name)
+`
+
+type ListBookmarkCollectionsRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Name          string
+	BookmarkCount int64
+}
+
+func (q *Queries) ListBookmarkCollections(ctx context.Context, userID uuid.UUID) ([]ListBookmarkCollectionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBookmarkCollections, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookmarkCollectionsRow
+	for rows.Next() {
+		var i ListBookmarkCollectionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.BookmarkCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookmarkedChirps = `-- name: ListBookmarkedChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at, bookmarks.created_at AS bookmarked_at
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.collection_id = $1
+  AND chirps.deleted_at IS NULL
+  AND (
+    $2::timestamp IS NULL
+    OR (bookmarks.created_at, bookmarks.chirp_id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT $4
+`
+
+type ListBookmarkedChirpsParams struct {
+	CollectionID    uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+type ListBookmarkedChirpsRow struct {
+	Chirp        Chirp
+	BookmarkedAt time.Time
+}
+
+func (q *Queries) ListBookmarkedChirps(ctx context.Context, arg ListBookmarkedChirpsParams) ([]ListBookmarkedChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBookmarkedChirps,
+		arg.CollectionID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookmarkedChirpsRow
+	for rows.Next() {
+		var i ListBookmarkedChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.RechirpOfID,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.EditedAt,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ScheduledFor,
+			&i.Chirp.ModeratedAt,
+			&i.BookmarkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeBookmark = `-- name: RemoveBookmark :execrows
+DELETE FROM bookmarks
+WHERE collection_id = $1 AND chirp_id = $2 AND user_id = $3
+`
+
+type RemoveBookmarkParams struct {
+	CollectionID uuid.UUID
+	ChirpID      uuid.UUID
+	UserID       uuid.UUID
+}
+
+func (q *Queries) RemoveBookmark(ctx context.Context, arg RemoveBookmarkParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeBookmark, arg.CollectionID, arg.ChirpID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameBookmarkCollection = `-- name: RenameBookmarkCollection :one
+UPDATE bookmark_collections
+SET name = $1, updated_at = NOW()
+WHERE bookmark_collections.id = $2 AND bookmark_collections.user_id = $3
+RETURNING
+    id, created_at, updated_at, user_id, name,
+    (
+        SELECT COUNT(*)
+        FROM bookmarks
+        JOIN chirps ON chirps.id = bookmarks.chirp_id
+        WHERE bookmarks.collection_id = bookmark_collections.id AND chirps.deleted_at IS NULL
+    ) AS bookmark_count
+`
+
+type RenameBookmarkCollectionParams struct {
+	Name   string
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type RenameBookmarkCollectionRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Name          string
+	BookmarkCount int64
+}
+
+func (q *Queries) RenameBookmarkCollection(ctx context.Context, arg RenameBookmarkCollectionParams) (RenameBookmarkCollectionRow, error) {
+	row := q.db.QueryRowContext(ctx, renameBookmarkCollection, arg.Name, arg.ID, arg.UserID)
+	var i RenameBookmarkCollectionRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.BookmarkCount,
+	)
+	return i, err
+}
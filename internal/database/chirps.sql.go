@@ -7,29 +7,61 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const cancelScheduledChirp = `-- name: CancelScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND scheduled_for IS NOT NULL
+`
+
+type CancelScheduledChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.NullUUID
+}
+
+func (q *Queries) CancelScheduledChirp(ctx context.Context, arg CancelScheduledChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelScheduledChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, quoted_chirp_id, scheduled_for)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.NullUUID
+	Body          string
+	UserID        uuid.NullUUID
+	ParentID      uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+	ScheduledFor  sql.NullTime
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.QuotedChirpID,
+		arg.ScheduledFor,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,32 +69,122 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
+    $2
+)
+ON CONFLICT (user_id, rechirp_of_id) WHERE rechirp_of_id IS NOT NULL DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+type CreateRechirpParams struct {
+	UserID      uuid.NullUUID
+	RechirpOfID uuid.NullUUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.RechirpOfID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :one
 DELETE FROM chirps
-WHERE id = $1 AND user_id = $2
+WHERE user_id = $1 AND rechirp_of_id = $2
+RETURNING id
 `
 
-type DeleteChirpParams struct {
-	ID     uuid.UUID
-	UserID uuid.NullUUID
+type DeleteRechirpParams struct {
+	UserID      uuid.NullUUID
+	RechirpOfID uuid.NullUUID
 }
 
-func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, arg.ID, arg.UserID)
-	return err
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, deleteRechirp, arg.UserID, arg.RechirpOfID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
-const getAllChirps = `-- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at
+const getChirp = `-- name: GetChirp :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL AND scheduled_for IS NULL
 `
 
-func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirps)
+func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.parent_id AS id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = $1
+    UNION ALL
+    SELECT chirps.parent_id, ancestors.depth + 1
+    FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.id
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at FROM chirps
+JOIN ancestors ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC
+`
+
+func (q *Queries) GetChirpAncestors(ctx context.Context, id uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, id)
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +198,15 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -90,14 +221,94 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getAllChirpsByAuthor = `-- name: GetAllChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextScheduledTime = `-- name: GetNextScheduledTime :one
+SELECT scheduled_for FROM chirps
+WHERE scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+ORDER BY scheduled_for
+LIMIT 1
 `
 
-func (q *Queries) GetAllChirpsByAuthor(ctx context.Context, userID uuid.NullUUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsByAuthor, userID)
+func (q *Queries) GetNextScheduledTime(ctx context.Context) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, getNextScheduledTime)
+	var scheduled_for sql.NullTime
+	err := row.Scan(&scheduled_for)
+	return scheduled_for, err
+}
+
+const listChirpReplies = `-- name: ListChirpReplies :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE chirps.parent_id = $1
+  AND chirps.scheduled_for IS NULL
+  AND (
+    chirps.deleted_at IS NULL
+    OR EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+  )
+  AND (
+    $2::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) > ($2::timestamp, $3::uuid)
+  )
+ORDER BY chirps.created_at, chirps.id
+LIMIT $4
+`
+
+type ListChirpRepliesParams struct {
+	ParentID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpReplies(ctx context.Context, arg ListChirpRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpReplies,
+		arg.ParentID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +322,15 @@ func (q *Queries) GetAllChirpsByAuthor(ctx context.Context, userID uuid.NullUUID
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -125,14 +345,210 @@ func (q *Queries) GetAllChirpsByAuthor(ctx context.Context, userID uuid.NullUUID
 	return items, nil
 }
 
-const getAllChirpsByAuthorDesc = `-- name: GetAllChirpsByAuthorDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+const listChirps = `-- name: ListChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = $1::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND ($2::uuid IS NULL OR user_id = $2::uuid)
+  AND ($3::uuid IS NULL OR id <> $3::uuid)
+  AND (
+    $4::timestamp IS NULL
+    OR (created_at, id) > ($4::timestamp, $5::uuid)
+  )
+ORDER BY created_at, id
+LIMIT $6
+`
+
+type ListChirpsParams struct {
+	ViewerID        uuid.NullUUID
+	AuthorID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirps(ctx context.Context, arg ListChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirps,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.ExcludeID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = $1::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND ($2::uuid IS NULL OR user_id = $2::uuid)
+  AND ($3::uuid IS NULL OR id <> $3::uuid)
+  AND (
+    $4::timestamp IS NULL
+    OR (created_at, id) < ($4::timestamp, $5::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListChirpsDescParams struct {
+	ViewerID        uuid.NullUUID
+	AuthorID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.ExcludeID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueChirpIDs = `-- name: ListDueChirpIDs :many
+SELECT id FROM chirps
+WHERE scheduled_for <= (NOW() AT TIME ZONE 'UTC')
+  AND deleted_at IS NULL
+ORDER BY scheduled_for, id
+LIMIT $1
+`
+
+func (q *Queries) ListDueChirpIDs(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listDueChirpIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledChirps = `-- name: ListScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
 WHERE user_id = $1
-ORDER BY created_at DESC
+  AND scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+  AND (
+    $2::timestamp IS NULL
+    OR (scheduled_for, id) > ($2::timestamp, $3::uuid)
+  )
+ORDER BY scheduled_for, id
+LIMIT $4
 `
 
-func (q *Queries) GetAllChirpsByAuthorDesc(ctx context.Context, userID uuid.NullUUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsByAuthorDesc, userID)
+type ListScheduledChirpsParams struct {
+	UserID             uuid.NullUUID
+	CursorScheduledFor sql.NullTime
+	CursorID           uuid.NullUUID
+	PageLimit          int32
+}
+
+func (q *Queries) ListScheduledChirps(ctx context.Context, arg ListScheduledChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledChirps,
+		arg.UserID,
+		arg.CursorScheduledFor,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +562,15 @@ func (q *Queries) GetAllChirpsByAuthorDesc(ctx context.Context, userID uuid.Null
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -160,13 +585,43 @@ func (q *Queries) GetAllChirpsByAuthorDesc(ctx context.Context, userID uuid.Null
 	return items, nil
 }
 
-const getAllChirpsDesc = `-- name: GetAllChirpsDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at DESC
+const listTimelineChirps = `-- name: ListTimelineChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE user_id IN (
+    SELECT followee_id FROM follows
+    WHERE follower_id = $1
+)
+  AND deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = $2::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $5
 `
 
-func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsDesc)
+type ListTimelineChirpsParams struct {
+	FollowerID      uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListTimelineChirps(ctx context.Context, arg ListTimelineChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTimelineChirps,
+		arg.FollowerID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +635,15 @@ func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -194,13 +658,17 @@ func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+const publishScheduledChirp = `-- name: PublishScheduledChirp :one
+UPDATE chirps
+SET scheduled_for = NULL, created_at = NOW(), updated_at = NOW()
 WHERE id = $1
+  AND scheduled_for <= (NOW() AT TIME ZONE 'UTC')
+  AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
 `
 
-func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirp, id)
+func (q *Queries) PublishScheduledChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, publishScheduledChirp, id)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -208,6 +676,222 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
 	)
 	return i, err
 }
+
+const purgeExpiredChirps = `-- name: PurgeExpiredChirps :execrows
+DELETE FROM chirps
+WHERE chirps.deleted_at < NOW() - ($1::int * INTERVAL '1 second')
+  AND NOT EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+`
+
+func (q *Queries) PurgeExpiredChirps(ctx context.Context, retentionSeconds int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredChirps, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rescheduleChirp = `-- name: RescheduleChirp :one
+UPDATE chirps
+SET scheduled_for = $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+  AND scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+type RescheduleChirpParams struct {
+	ScheduledFor sql.NullTime
+	ID           uuid.UUID
+	UserID       uuid.NullUUID
+}
+
+func (q *Queries) RescheduleChirp(ctx context.Context, arg RescheduleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleChirp, arg.ScheduledFor, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+  AND tombstoned_at IS NULL
+  AND moderated_at IS NULL
+  AND deleted_at > NOW() - ($3::int * INTERVAL '1 second')
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	UserID       uuid.NullUUID
+	GraceSeconds int32
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.GraceSeconds)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at, ts_rank(chirps.search_vector, to_tsquery('english', $1)) AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', $1)
+  AND chirps.deleted_at IS NULL
+  AND chirps.scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = $2::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND ($3::uuid IS NULL OR chirps.user_id = $3::uuid)
+  AND (
+    $4::real IS NULL
+    OR (ts_rank(chirps.search_vector, to_tsquery('english', $1)), chirps.created_at, chirps.id)
+      < ($4::real, $5::timestamp, $6::uuid)
+  )
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $7
+`
+
+type SearchChirpsParams struct {
+	Query           string
+	ViewerID        uuid.NullUUID
+	AuthorID        uuid.NullUUID
+	CursorRank      sql.NullFloat64
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+type SearchChirpsRow struct {
+	Chirp Chirp
+	Rank  float32
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.CursorRank,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.RechirpOfID,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.EditedAt,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ScheduledFor,
+			&i.Chirp.ModeratedAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :execrows
+UPDATE chirps
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND scheduled_for IS NULL
+`
+
+type SoftDeleteChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.NullUUID
+}
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const tombstoneExpiredChirps = `-- name: TombstoneExpiredChirps :execrows
+UPDATE chirps
+SET body = '', tombstoned_at = NOW()
+WHERE chirps.deleted_at < NOW() - ($1::int * INTERVAL '1 second')
+  AND chirps.tombstoned_at IS NULL
+  AND EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+`
+
+func (q *Queries) TombstoneExpiredChirps(ctx context.Context, retentionSeconds int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, tombstoneExpiredChirps, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,198 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: drafts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createDraft = `-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id
+`
+
+type CreateDraftParams struct {
+	UserID        uuid.UUID
+	Body          string
+	ParentID      uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+}
+
+func (q *Queries) CreateDraft(ctx context.Context, arg CreateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, createDraft,
+		arg.UserID,
+		arg.Body,
+		arg.ParentID,
+		arg.QuotedChirpID,
+	)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.ParentID,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const deleteDraft = `-- name: DeleteDraft :one
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2
+RETURNING id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id
+`
+
+type DeleteDraftParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteDraft(ctx context.Context, arg DeleteDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, deleteDraft, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.ParentID,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const getDraft = `-- name: GetDraft :one
+SELECT id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id FROM drafts
+WHERE id = $1 AND user_id = $2
+`
+
+type GetDraftParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetDraft(ctx context.Context, arg GetDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraft, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.ParentID,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const listDrafts = `-- name: ListDrafts :many
+SELECT id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id FROM drafts
+WHERE user_id = $1
+  AND (
+    $2::timestamp IS NULL
+    OR (updated_at, id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY updated_at DESC, id DESC
+LIMIT $4
+`
+
+type ListDraftsParams struct {
+	UserID          uuid.UUID
+	CursorUpdatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListDrafts(ctx context.Context, arg ListDraftsParams) ([]Draft, error) {
+	rows, err := q.db.QueryContext(ctx, listDrafts,
+		arg.UserID,
+		arg.CursorUpdatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Draft
+	for rows.Next() {
+		var i Draft
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Body,
+			&i.ParentID,
+			&i.QuotedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateDraft = `-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $1,
+    parent_id = $2,
+    quoted_chirp_id = $3,
+    updated_at = NOW()
+WHERE id = $4 AND user_id = $5
+RETURNING id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id
+`
+
+type UpdateDraftParams struct {
+	Body          string
+	ParentID      uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+	ID            uuid.UUID
+	UserID        uuid.UUID
+}
+
+func (q *Queries) UpdateDraft(ctx context.Context, arg UpdateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, updateDraft,
+		arg.Body,
+		arg.ParentID,
+		arg.QuotedChirpID,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.ParentID,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
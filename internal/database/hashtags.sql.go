@@ -0,0 +1,170 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: hashtags.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const addChirpHashtag = `-- name: AddChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING
+`
+
+type AddChirpHashtagParams struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+}
+
+func (q *Queries) AddChirpHashtag(ctx context.Context, arg AddChirpHashtagParams) error {
+	_, err := q.db.ExecContext(ctx, addChirpHashtag, arg.ChirpID, arg.HashtagID)
+	return err
+}
+
+const clearChirpHashtags = `-- name: ClearChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1
+`
+
+func (q *Queries) ClearChirpHashtags(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearChirpHashtags, chirpID)
+	return err
+}
+
+const getTrendingHashtags = `-- name: GetTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS chirp_count
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.created_at > NOW() - ($1::int * INTERVAL '1 second')
+  AND chirps.deleted_at IS NULL
+GROUP BY hashtags.tag
+ORDER BY chirp_count DESC, hashtags.tag
+LIMIT $2
+`
+
+type GetTrendingHashtagsParams struct {
+	WindowSeconds int32
+	TagLimit      int32
+}
+
+type GetTrendingHashtagsRow struct {
+	Tag        string
+	ChirpCount int64
+}
+
+func (q *Queries) GetTrendingHashtags(ctx context.Context, arg GetTrendingHashtagsParams) ([]GetTrendingHashtagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrendingHashtags, arg.WindowSeconds, arg.TagLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrendingHashtagsRow
+	for rows.Next() {
+		var i GetTrendingHashtagsRow
+		if err := rows.Scan(&i.Tag, &i.ChirpCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByHashtag = `-- name: ListChirpsByHashtag :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = $1
+  AND chirps.deleted_at IS NULL
+  AND (
+    $2::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type ListChirpsByHashtagParams struct {
+	Tag             string
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpsByHashtag(ctx context.Context, arg ListChirpsByHashtagParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByHashtag,
+		arg.Tag,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertHashtag = `-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, created_at, tag)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1
+)
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING id, created_at, tag
+`
+
+func (q *Queries) UpsertHashtag(ctx context.Context, tag string) (Hashtag, error) {
+	row := q.db.QueryRowContext(ctx, upsertHashtag, tag)
+	var i Hashtag
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.Tag)
+	return i, err
+}
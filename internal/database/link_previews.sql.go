@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: link_previews.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const getLinkPreviews = `-- name: GetLinkPreviews :many
+SELECT url, fetched_at, failed, title, description, image_url, site_name FROM link_previews
+WHERE url = ANY($1::text[])
+  AND NOT failed
+`
+
+func (q *Queries) GetLinkPreviews(ctx context.Context, urls []string) ([]LinkPreview, error) {
+	rows, err := q.db.QueryContext(ctx, getLinkPreviews, pq.Array(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LinkPreview
+	for rows.Next() {
+		var i LinkPreview
+		if err := rows.Scan(
+			&i.Url,
+			&i.FetchedAt,
+			&i.Failed,
+			&i.Title,
+			&i.Description,
+			&i.ImageUrl,
+			&i.SiteName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isLinkPreviewFresh = `-- name: IsLinkPreviewFresh :one
+SELECT EXISTS (
+    SELECT 1 FROM link_previews
+    WHERE url = $1
+      AND fetched_at > NOW() - ($2::int * INTERVAL '1 second')
+)
+`
+
+type IsLinkPreviewFreshParams struct {
+	Url           string
+	MaxAgeSeconds int32
+}
+
+func (q *Queries) IsLinkPreviewFresh(ctx context.Context, arg IsLinkPreviewFreshParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isLinkPreviewFresh, arg.Url, arg.MaxAgeSeconds)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const upsertLinkPreview = `-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (url, fetched_at, failed, title, description, image_url, site_name)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (url) DO UPDATE
+SET fetched_at = NOW(),
+    failed = EXCLUDED.failed,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    site_name = EXCLUDED.site_name
+`
+
+type UpsertLinkPreviewParams struct {
+	Url         string
+	Failed      bool
+	Title       string
+	Description string
+	ImageUrl    string
+	SiteName    string
+}
+
+func (q *Queries) UpsertLinkPreview(ctx context.Context, arg UpsertLinkPreviewParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLinkPreview,
+		arg.Url,
+		arg.Failed,
+		arg.Title,
+		arg.Description,
+		arg.ImageUrl,
+		arg.SiteName,
+	)
+	return err
+}
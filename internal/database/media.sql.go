@@ -0,0 +1,298 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: media.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachMedia = `-- name: AttachMedia :execrows
+UPDATE media
+SET chirp_id = $1,
+    position = array_position($2::uuid[], id)
+WHERE id = ANY($2::uuid[])
+  AND user_id = $3
+  AND chirp_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_media_id = media.id)
+`
+
+type AttachMediaParams struct {
+	ChirpID uuid.NullUUID
+	Ids     []uuid.UUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) AttachMedia(ctx context.Context, arg AttachMediaParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, attachMedia, arg.ChirpID, pq.Array(arg.Ids), arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createMedia = `-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key
+`
+
+type CreateMediaParams struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	ContentType  string
+	SizeBytes    int64
+	Width        int32
+	Height       int32
+	StorageKey   string
+	ThumbnailKey string
+}
+
+func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Media, error) {
+	row := q.db.QueryRowContext(ctx, createMedia,
+		arg.ID,
+		arg.UserID,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.StorageKey,
+		arg.ThumbnailKey,
+	)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ChirpID,
+		&i.Position,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+	)
+	return i, err
+}
+
+const deleteAbandonedMedia = `-- name: DeleteAbandonedMedia :many
+DELETE FROM media
+WHERE chirp_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_media_id = media.id)
+  AND created_at < NOW() - ($1::int * INTERVAL '1 second')
+RETURNING id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key
+`
+
+func (q *Queries) DeleteAbandonedMedia(ctx context.Context, maxAgeSeconds int32) ([]Media, error) {
+	rows, err := q.db.QueryContext(ctx, deleteAbandonedMedia, maxAgeSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteExpiredChirpMedia = `-- name: DeleteExpiredChirpMedia :many
+DELETE FROM media
+WHERE chirp_id IN (
+    SELECT id FROM chirps
+    WHERE chirps.deleted_at < NOW() - ($1::int * INTERVAL '1 second')
+)
+RETURNING id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key
+`
+
+func (q *Queries) DeleteExpiredChirpMedia(ctx context.Context, retentionSeconds int32) ([]Media, error) {
+	rows, err := q.db.QueryContext(ctx, deleteExpiredChirpMedia, retentionSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteScheduledChirpMedia = `-- name: DeleteScheduledChirpMedia :many
+DELETE FROM media
+WHERE chirp_id IN (
+    SELECT id FROM chirps
+    WHERE chirps.id = $1 AND chirps.user_id = $2 AND chirps.scheduled_for IS NOT NULL
+)
+RETURNING id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key
+`
+
+type DeleteScheduledChirpMediaParams struct {
+	ID     uuid.UUID
+	UserID uuid.NullUUID
+}
+
+func (q *Queries) DeleteScheduledChirpMedia(ctx context.Context, arg DeleteScheduledChirpMediaParams) ([]Media, error) {
+	rows, err := q.db.QueryContext(ctx, deleteScheduledChirpMedia, arg.ID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMediaForChirps = `-- name: GetMediaForChirps :many
+SELECT id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key FROM media
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, position
+`
+
+func (q *Queries) GetMediaForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]Media, error) {
+	rows, err := q.db.QueryContext(ctx, getMediaForChirps, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnattachedMedia = `-- name: GetUnattachedMedia :one
+SELECT id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key FROM media
+WHERE id = $1 AND user_id = $2 AND chirp_id IS NULL
+`
+
+type GetUnattachedMediaParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetUnattachedMedia(ctx context.Context, arg GetUnattachedMediaParams) (Media, error) {
+	row := q.db.QueryRowContext(ctx, getUnattachedMedia, arg.ID, arg.UserID)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ChirpID,
+		&i.Position,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+	)
+	return i, err
+}
@@ -0,0 +1,204 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: mentions.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const clearChirpMentions = `-- name: ClearChirpMentions :many
+DELETE FROM mentions
+WHERE chirp_id = $1
+RETURNING user_id
+`
+
+func (q *Queries) ClearChirpMentions(ctx context.Context, chirpID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, clearChirpMentions, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createMention = `-- name: CreateMention :exec
+INSERT INTO mentions (chirp_id, user_id, start_offset, end_offset, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+`
+
+type CreateMentionParams struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+func (q *Queries) CreateMention(ctx context.Context, arg CreateMentionParams) error {
+	_, err := q.db.ExecContext(ctx, createMention,
+		arg.ChirpID,
+		arg.UserID,
+		arg.StartOffset,
+		arg.EndOffset,
+	)
+	return err
+}
+
+const findUsersByUsername = `-- name: FindUsersByUsername :many
+SELECT id, LOWER(username)::text AS handle FROM users
+WHERE LOWER(username) = ANY($1::text[])
+`
+
+type FindUsersByUsernameRow struct {
+	ID     uuid.UUID
+	Handle string
+}
+
+func (q *Queries) FindUsersByUsername(ctx context.Context, handles []string) ([]FindUsersByUsernameRow, error) {
+	rows, err := q.db.QueryContext(ctx, findUsersByUsername, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindUsersByUsernameRow
+	for rows.Next() {
+		var i FindUsersByUsernameRow
+		if err := rows.Scan(&i.ID, &i.Handle); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMentionsForChirps = `-- name: GetMentionsForChirps :many
+SELECT chirp_id, user_id, start_offset, end_offset, created_at FROM mentions
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, start_offset
+`
+
+func (q *Queries) GetMentionsForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]Mention, error) {
+	rows, err := q.db.QueryContext(ctx, getMentionsForChirps, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Mention
+	for rows.Next() {
+		var i Mention
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.UserID,
+			&i.StartOffset,
+			&i.EndOffset,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMentioningChirps = `-- name: ListMentioningChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE id IN (
+    SELECT chirp_id FROM mentions
+    WHERE mentions.user_id = $1
+)
+  AND deleted_at IS NULL
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListMentioningChirpsParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListMentioningChirps(ctx context.Context, arg ListMentioningChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listMentioningChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.RechirpOfID,
+			&i.QuotedChirpID,
+			&i.SearchVector,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.ScheduledFor,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
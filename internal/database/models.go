@@ -11,12 +11,182 @@ import (
 	"github.com/google/uuid"
 )
 
-type Chirp struct {
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type Bookmark struct {
+	CollectionID uuid.UUID
+	ChirpID      uuid.UUID
+	UserID       uuid.UUID
+	CreatedAt    time.Time
+}
+
+type BookmarkCollection struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.NullUUID
+	UserID    uuid.UUID
+	Name      string
+}
+
+type Chirp struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	ParentID      uuid.NullUUID
+	TombstonedAt  sql.NullTime
+	RechirpOfID   uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+	SearchVector  interface{}
+	EditedAt      sql.NullTime
+	DeletedAt     sql.NullTime
+	ScheduledFor  sql.NullTime
+	ModeratedAt   sql.NullTime
+}
+
+type ChirpFlag struct {
+	ChirpID   uuid.UUID
+	Words     []string
+	CreatedAt time.Time
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type Draft struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Body          string
+	ParentID      uuid.NullUUID
+	QuotedChirpID uuid.NullUUID
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Tag       string
+}
+
+type HiddenAuthor struct {
+	ViewerID uuid.UUID
+	AuthorID uuid.UUID
+}
+
+type LinkPreview struct {
+	Url         string
+	FetchedAt   time.Time
+	Failed      bool
+	Title       string
+	Description string
+	ImageUrl    string
+	SiteName    string
+}
+
+type Media struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UserID       uuid.UUID
+	ChirpID      uuid.NullUUID
+	Position     int32
+	ContentType  string
+	SizeBytes    int64
+	Width        int32
+	Height       int32
+	StorageKey   string
+	ThumbnailKey string
+}
+
+type Mention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+	CreatedAt   time.Time
+}
+
+type ModerationAction struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ModeratorID uuid.NullUUID
+	Action      string
+	ChirpID     uuid.NullUUID
+	ChirpUserID uuid.NullUUID
+	ChirpBody   string
+	ReportIds   []uuid.UUID
+	Note        string
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Type      string
+	ActorID   uuid.NullUUID
+	ChirpID   uuid.NullUUID
+	ReadAt    sql.NullTime
+}
+
+type Poll struct {
+	ChirpID     uuid.UUID
+	CreatedAt   time.Time
+	ClosesAt    time.Time
+	HideResults bool
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	ChirpID  uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type ProfanityWord struct {
+	Word      string
+	Kind      string
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,6 +198,17 @@ type RefreshToken struct {
 	UserID    uuid.UUID
 }
 
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ChirpID    uuid.NullUUID
+	ReporterID uuid.UUID
+	Reason     string
+	Status     string
+	ResolvedAt sql.NullTime
+	ResolvedBy uuid.NullUUID
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -35,4 +216,10 @@ type User struct {
 	Email          string
 	HashedPassword sql.NullString
 	IsChirpyRed    sql.NullBool
+	IsAdmin        bool
+	PinnedChirpID  uuid.NullUUID
+	Username       sql.NullString
+	DisplayName    string
+	Bio            string
+	AvatarMediaID  uuid.NullUUID
 }
@@ -0,0 +1,288 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: moderation.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createModerationAction = `-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, moderator_id, action, chirp_id, chirp_user_id, chirp_body, report_ids, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $7::uuid[],
+    $6
+)
+RETURNING id, created_at, moderator_id, action, chirp_id, chirp_user_id, chirp_body, report_ids, note
+`
+
+type CreateModerationActionParams struct {
+	ModeratorID uuid.NullUUID
+	Action      string
+	ChirpID     uuid.NullUUID
+	ChirpUserID uuid.NullUUID
+	ChirpBody   string
+	Note        string
+	ReportIds   []uuid.UUID
+}
+
+func (q *Queries) CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) (ModerationAction, error) {
+	row := q.db.QueryRowContext(ctx, createModerationAction,
+		arg.ModeratorID,
+		arg.Action,
+		arg.ChirpID,
+		arg.ChirpUserID,
+		arg.ChirpBody,
+		arg.Note,
+		pq.Array(arg.ReportIds),
+	)
+	var i ModerationAction
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ModeratorID,
+		&i.Action,
+		&i.ChirpID,
+		&i.ChirpUserID,
+		&i.ChirpBody,
+		pq.Array(&i.ReportIds),
+		&i.Note,
+	)
+	return i, err
+}
+
+const deleteChirp = `-- name: DeleteChirp :execrows
+DELETE FROM chirps
+WHERE chirps.id = $1
+  AND NOT EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+`
+
+func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteChirpMedia = `-- name: DeleteChirpMedia :many
+DELETE FROM media
+WHERE chirp_id = $1
+RETURNING id, created_at, user_id, chirp_id, position, content_type, size_bytes, width, height, storage_key, thumbnail_key
+`
+
+func (q *Queries) DeleteChirpMedia(ctx context.Context, chirpID uuid.NullUUID) ([]Media, error) {
+	rows, err := q.db.QueryContext(ctx, deleteChirpMedia, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteChirpRevisions = `-- name: DeleteChirpRevisions :exec
+DELETE FROM chirp_revisions
+WHERE chirp_id = $1
+`
+
+func (q *Queries) DeleteChirpRevisions(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpRevisions, chirpID)
+	return err
+}
+
+const getChirpForModeration = `-- name: GetChirpForModeration :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForModeration(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForModeration, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const hideChirp = `-- name: HideChirp :one
+UPDATE chirps
+SET deleted_at = NOW(), moderated_at = NOW(), updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NULL
+  AND scheduled_for IS NULL
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, hideChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const listModerationActions = `-- name: ListModerationActions :many
+SELECT id, created_at, moderator_id, action, chirp_id, chirp_user_id, chirp_body, report_ids, note FROM moderation_actions
+WHERE (
+    $1::timestamp IS NULL
+    OR (created_at, id) < ($1::timestamp, $2::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListModerationActionsParams struct {
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListModerationActions(ctx context.Context, arg ListModerationActionsParams) ([]ModerationAction, error) {
+	rows, err := q.db.QueryContext(ctx, listModerationActions, arg.CursorCreatedAt, arg.CursorID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModerationAction
+	for rows.Next() {
+		var i ModerationAction
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ModeratorID,
+			&i.Action,
+			&i.ChirpID,
+			&i.ChirpUserID,
+			&i.ChirpBody,
+			pq.Array(&i.ReportIds),
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tombstoneChirp = `-- name: TombstoneChirp :execrows
+UPDATE chirps
+SET body = '',
+    tombstoned_at = NOW(),
+    deleted_at = COALESCE(chirps.deleted_at, NOW()),
+    moderated_at = NOW(),
+    updated_at = NOW()
+WHERE chirps.id = $1
+  AND EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+`
+
+func (q *Queries) TombstoneChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, tombstoneChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unhideChirp = `-- name: UnhideChirp :one
+UPDATE chirps
+SET deleted_at = NULL, moderated_at = NULL, updated_at = NOW()
+WHERE id = $1
+  AND moderated_at IS NOT NULL
+  AND tombstoned_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+func (q *Queries) UnhideChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, unhideChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: polls.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const castPollVote = `-- name: CastPollVote :execrows
+INSERT INTO poll_votes (chirp_id, user_id, option_id, created_at)
+SELECT poll_options.chirp_id, $1, poll_options.id, NOW()
+FROM poll_options
+JOIN polls ON polls.chirp_id = poll_options.chirp_id
+WHERE poll_options.id = $2
+  AND poll_options.chirp_id = $3
+  AND polls.closes_at > (NOW() AT TIME ZONE 'UTC')
+ON CONFLICT DO NOTHING
+`
+
+type CastPollVoteParams struct {
+	UserID   uuid.UUID
+	OptionID uuid.UUID
+	ChirpID  uuid.UUID
+}
+
+func (q *Queries) CastPollVote(ctx context.Context, arg CastPollVoteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, castPollVote, arg.UserID, arg.OptionID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createPoll = `-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, closes_at, hide_results)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3
+)
+`
+
+type CreatePollParams struct {
+	ChirpID     uuid.UUID
+	ClosesAt    time.Time
+	HideResults bool
+}
+
+func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) error {
+	_, err := q.db.ExecContext(ctx, createPoll, arg.ChirpID, arg.ClosesAt, arg.HideResults)
+	return err
+}
+
+const createPollOptions = `-- name: CreatePollOptions :exec
+INSERT INTO poll_options (id, chirp_id, position, text)
+SELECT gen_random_uuid(), $1, options.position::int, options.text
+FROM unnest($2::text[]) WITH ORDINALITY AS options(text, position)
+`
+
+type CreatePollOptionsParams struct {
+	ChirpID uuid.UUID
+	Texts   []string
+}
+
+func (q *Queries) CreatePollOptions(ctx context.Context, arg CreatePollOptionsParams) error {
+	_, err := q.db.ExecContext(ctx, createPollOptions, arg.ChirpID, pq.Array(arg.Texts))
+	return err
+}
+
+const getPoll = `-- name: GetPoll :one
+SELECT chirp_id, created_at, closes_at, hide_results FROM polls
+WHERE chirp_id = $1
+`
+
+func (q *Queries) GetPoll(ctx context.Context, chirpID uuid.UUID) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, getPoll, chirpID)
+	var i Poll
+	err := row.Scan(
+		&i.ChirpID,
+		&i.CreatedAt,
+		&i.ClosesAt,
+		&i.HideResults,
+	)
+	return i, err
+}
+
+const getPollResults = `-- name: GetPollResults :many
+SELECT
+    poll_options.id,
+    poll_options.chirp_id,
+    poll_options.text,
+    COUNT(poll_votes.user_id) AS vote_count,
+    COALESCE(BOOL_OR(poll_votes.user_id = $1::uuid), false)::boolean AS voted_by_me
+FROM poll_options
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE poll_options.chirp_id = ANY($2::uuid[])
+GROUP BY poll_options.id
+ORDER BY poll_options.chirp_id, poll_options.position
+`
+
+type GetPollResultsParams struct {
+	ViewerID uuid.NullUUID
+	ChirpIds []uuid.UUID
+}
+
+type GetPollResultsRow struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Text      string
+	VoteCount int64
+	VotedByMe bool
+}
+
+func (q *Queries) GetPollResults(ctx context.Context, arg GetPollResultsParams) ([]GetPollResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPollResults, arg.ViewerID, pq.Array(arg.ChirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPollResultsRow
+	for rows.Next() {
+		var i GetPollResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChirpID,
+			&i.Text,
+			&i.VoteCount,
+			&i.VotedByMe,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollsForChirps = `-- name: GetPollsForChirps :many
+SELECT chirp_id, created_at, closes_at, hide_results FROM polls
+WHERE chirp_id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetPollsForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]Poll, error) {
+	rows, err := q.db.QueryContext(ctx, getPollsForChirps, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.CreatedAt,
+			&i.ClosesAt,
+			&i.HideResults,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
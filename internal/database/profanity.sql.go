@@ -0,0 +1,177 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: profanity.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const addProfanityWord = `-- name: AddProfanityWord :exec
+INSERT INTO profanity_words (word, kind, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING
+`
+
+type AddProfanityWordParams struct {
+	Word string
+	Kind string
+}
+
+func (q *Queries) AddProfanityWord(ctx context.Context, arg AddProfanityWordParams) error {
+	_, err := q.db.ExecContext(ctx, addProfanityWord, arg.Word, arg.Kind)
+	return err
+}
+
+const deleteChirpFlag = `-- name: DeleteChirpFlag :exec
+DELETE FROM chirp_flags
+WHERE chirp_id = $1
+`
+
+func (q *Queries) DeleteChirpFlag(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpFlag, chirpID)
+	return err
+}
+
+const deleteProfanityWord = `-- name: DeleteProfanityWord :execrows
+DELETE FROM profanity_words
+WHERE word = $1 AND kind = $2
+`
+
+type DeleteProfanityWordParams struct {
+	Word string
+	Kind string
+}
+
+func (q *Queries) DeleteProfanityWord(ctx context.Context, arg DeleteProfanityWordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteProfanityWord, arg.Word, arg.Kind)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const flagChirp = `-- name: FlagChirp :exec
+INSERT INTO chirp_flags (chirp_id, words, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (chirp_id) DO UPDATE
+SET words = EXCLUDED.words, created_at = NOW()
+`
+
+type FlagChirpParams struct {
+	ChirpID uuid.UUID
+	Words   []string
+}
+
+func (q *Queries) FlagChirp(ctx context.Context, arg FlagChirpParams) error {
+	_, err := q.db.ExecContext(ctx, flagChirp, arg.ChirpID, pq.Array(arg.Words))
+	return err
+}
+
+const listChirpFlags = `-- name: ListChirpFlags :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at, chirp_flags.words, chirp_flags.created_at AS flagged_at
+FROM chirp_flags
+JOIN chirps ON chirps.id = chirp_flags.chirp_id
+WHERE chirps.deleted_at IS NULL
+  AND (
+    $1::timestamp IS NULL
+    OR (chirp_flags.created_at, chirp_flags.chirp_id) < ($1::timestamp, $2::uuid)
+  )
+ORDER BY chirp_flags.created_at DESC, chirp_flags.chirp_id DESC
+LIMIT $3
+`
+
+type ListChirpFlagsParams struct {
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+type ListChirpFlagsRow struct {
+	Chirp     Chirp
+	Words     []string
+	FlaggedAt time.Time
+}
+
+func (q *Queries) ListChirpFlags(ctx context.Context, arg ListChirpFlagsParams) ([]ListChirpFlagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpFlags, arg.CursorCreatedAt, arg.CursorID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpFlagsRow
+	for rows.Next() {
+		var i ListChirpFlagsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.RechirpOfID,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.EditedAt,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ScheduledFor,
+			&i.Chirp.ModeratedAt,
+			pq.Array(&i.Words),
+			&i.FlaggedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProfanityWords = `-- name: ListProfanityWords :many
+SELECT word, kind, created_at FROM profanity_words
+ORDER BY kind, word
+`
+
+func (q *Queries) ListProfanityWords(ctx context.Context) ([]ProfanityWord, error) {
+	rows, err := q.db.QueryContext(ctx, listProfanityWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProfanityWord
+	for rows.Next() {
+		var i ProfanityWord
+		if err := rows.Scan(&i.Word, &i.Kind, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
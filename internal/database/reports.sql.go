@@ -0,0 +1,166 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: reports.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createReport = `-- name: CreateReport :one
+INSERT INTO reports (id, created_at, chirp_id, reporter_id, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (chirp_id, reporter_id) WHERE status = 'open' DO NOTHING
+RETURNING id, created_at, chirp_id, reporter_id, reason, status, resolved_at, resolved_by
+`
+
+type CreateReportParams struct {
+	ChirpID    uuid.NullUUID
+	ReporterID uuid.UUID
+	Reason     string
+}
+
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, createReport, arg.ChirpID, arg.ReporterID, arg.Reason)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ChirpID,
+		&i.ReporterID,
+		&i.Reason,
+		&i.Status,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+	)
+	return i, err
+}
+
+const dismissReport = `-- name: DismissReport :one
+UPDATE reports
+SET status = 'dismissed', resolved_at = NOW(), resolved_by = $1
+WHERE id = $2 AND status = 'open'
+RETURNING id, created_at, chirp_id, reporter_id, reason, status, resolved_at, resolved_by
+`
+
+type DismissReportParams struct {
+	ModeratorID uuid.NullUUID
+	ID          uuid.UUID
+}
+
+func (q *Queries) DismissReport(ctx context.Context, arg DismissReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, dismissReport, arg.ModeratorID, arg.ID)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ChirpID,
+		&i.ReporterID,
+		&i.Reason,
+		&i.Status,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+	)
+	return i, err
+}
+
+const listReports = `-- name: ListReports :many
+SELECT id, created_at, chirp_id, reporter_id, reason, status, resolved_at, resolved_by FROM reports
+WHERE status = $1
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) > ($2::timestamp, $3::uuid)
+  )
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListReportsParams struct {
+	Status          string
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListReports(ctx context.Context, arg ListReportsParams) ([]Report, error) {
+	rows, err := q.db.QueryContext(ctx, listReports,
+		arg.Status,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Report
+	for rows.Next() {
+		var i Report
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ChirpID,
+			&i.ReporterID,
+			&i.Reason,
+			&i.Status,
+			&i.ResolvedAt,
+			&i.ResolvedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveChirpReports = `-- name: ResolveChirpReports :many
+UPDATE reports
+SET status = 'resolved', resolved_at = NOW(), resolved_by = $1
+WHERE chirp_id = $2 AND status = 'open'
+RETURNING id
+`
+
+type ResolveChirpReportsParams struct {
+	ModeratorID uuid.NullUUID
+	ChirpID     uuid.NullUUID
+}
+
+func (q *Queries) ResolveChirpReports(ctx context.Context, arg ResolveChirpReportsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, resolveChirpReports, arg.ModeratorID, arg.ChirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
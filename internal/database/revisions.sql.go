@@ -0,0 +1,159 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: revisions.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createChirpRevision = `-- name: CreateChirpRevision :exec
+INSERT INTO chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+`
+
+type CreateChirpRevisionParams struct {
+	ChirpID   uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateChirpRevision(ctx context.Context, arg CreateChirpRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpRevision, arg.ChirpID, arg.Body, arg.CreatedAt)
+	return err
+}
+
+const deleteTombstonedChirpRevisions = `-- name: DeleteTombstonedChirpRevisions :execrows
+DELETE FROM chirp_revisions
+WHERE chirp_id IN (SELECT id FROM chirps WHERE tombstoned_at IS NOT NULL)
+`
+
+func (q *Queries) DeleteTombstonedChirpRevisions(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTombstonedChirpRevisions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getChirpForEdit = `-- name: GetChirpForEdit :one
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at,
+    (chirps.created_at > NOW() - ($1::int * INTERVAL '1 second'))::boolean AS editable
+FROM chirps
+WHERE chirps.id = $2
+FOR UPDATE
+`
+
+type GetChirpForEditParams struct {
+	WindowSeconds int32
+	ID            uuid.UUID
+}
+
+type GetChirpForEditRow struct {
+	Chirp    Chirp
+	Editable bool
+}
+
+func (q *Queries) GetChirpForEdit(ctx context.Context, arg GetChirpForEditParams) (GetChirpForEditRow, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForEdit, arg.WindowSeconds, arg.ID)
+	var i GetChirpForEditRow
+	err := row.Scan(
+		&i.Chirp.ID,
+		&i.Chirp.CreatedAt,
+		&i.Chirp.UpdatedAt,
+		&i.Chirp.Body,
+		&i.Chirp.UserID,
+		&i.Chirp.ParentID,
+		&i.Chirp.TombstonedAt,
+		&i.Chirp.RechirpOfID,
+		&i.Chirp.QuotedChirpID,
+		&i.Chirp.SearchVector,
+		&i.Chirp.EditedAt,
+		&i.Chirp.DeletedAt,
+		&i.Chirp.ScheduledFor,
+		&i.Chirp.ModeratedAt,
+		&i.Editable,
+	)
+	return i, err
+}
+
+const listChirpRevisions = `-- name: ListChirpRevisions :many
+SELECT id, chirp_id, body, created_at, replaced_at FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY replaced_at DESC
+`
+
+func (q *Queries) ListChirpRevisions(ctx context.Context, chirpID uuid.UUID) ([]ChirpRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpRevisions, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpRevision
+	for rows.Next() {
+		var i ChirpRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChirpID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.ReplacedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, edited_at = NOW(), updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, body, user_id, parent_id, tombstoned_at, rechirp_of_id, quoted_chirp_id, search_vector, edited_at, deleted_at, scheduled_for, moderated_at
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
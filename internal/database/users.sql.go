@@ -11,27 +11,30 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, pinned_chirp_id, username, display_name, bio, avatar_media_id
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword sql.NullString
+	Username       sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -40,6 +43,12 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.PinnedChirpID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
@@ -64,7 +73,7 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 }
 
 const findUser = `-- name: FindUser :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users 
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, pinned_chirp_id, username, display_name, bio, avatar_media_id FROM users 
 WHERE email = $1
 `
 
@@ -78,10 +87,256 @@ func (q *Queries) FindUser(ctx context.Context, email string) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.PinnedChirpID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
 
+const getPinnedChirp = `-- name: GetPinnedChirp :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.tombstoned_at, chirps.rechirp_of_id, chirps.quoted_chirp_id, chirps.search_vector, chirps.edited_at, chirps.deleted_at, chirps.scheduled_for, chirps.moderated_at FROM users
+JOIN chirps ON chirps.id = users.pinned_chirp_id
+WHERE users.id = $1
+  AND chirps.deleted_at IS NULL
+  AND chirps.scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = $2::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+`
+
+type GetPinnedChirpParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetPinnedChirp(ctx context.Context, arg GetPinnedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getPinnedChirp, arg.UserID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.RechirpOfID,
+		&i.QuotedChirpID,
+		&i.SearchVector,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.ScheduledFor,
+		&i.ModeratedAt,
+	)
+	return i, err
+}
+
+const getProfile = `-- name: GetProfile :one
+SELECT
+    users.id,
+    users.created_at,
+    users.username,
+    users.display_name,
+    users.bio,
+    users.is_chirpy_red,
+    users.pinned_chirp_id,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE users.id = $1
+`
+
+type GetProfileRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	Username      sql.NullString
+	DisplayName   string
+	Bio           string
+	IsChirpyRed   sql.NullBool
+	PinnedChirpID uuid.NullUUID
+	AvatarKey     sql.NullString
+}
+
+func (q *Queries) GetProfile(ctx context.Context, id uuid.UUID) (GetProfileRow, error) {
+	row := q.db.QueryRowContext(ctx, getProfile, id)
+	var i GetProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.IsChirpyRed,
+		&i.PinnedChirpID,
+		&i.AvatarKey,
+	)
+	return i, err
+}
+
+const getProfileByUsername = `-- name: GetProfileByUsername :one
+SELECT
+    users.id,
+    users.created_at,
+    users.username,
+    users.display_name,
+    users.bio,
+    users.is_chirpy_red,
+    users.pinned_chirp_id,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE LOWER(users.username) = LOWER($1)
+`
+
+type GetProfileByUsernameRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	Username      sql.NullString
+	DisplayName   string
+	Bio           string
+	IsChirpyRed   sql.NullBool
+	PinnedChirpID uuid.NullUUID
+	AvatarKey     sql.NullString
+}
+
+func (q *Queries) GetProfileByUsername(ctx context.Context, username string) (GetProfileByUsernameRow, error) {
+	row := q.db.QueryRowContext(ctx, getProfileByUsername, username)
+	var i GetProfileByUsernameRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.IsChirpyRed,
+		&i.PinnedChirpID,
+		&i.AvatarKey,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, pinned_chirp_id, username, display_name, bio, avatar_media_id FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.PinnedChirpID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
+const getUserSummaries = `-- name: GetUserSummaries :many
+SELECT
+    users.id,
+    users.username,
+    users.display_name,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE users.id = ANY($1::uuid[])
+`
+
+type GetUserSummariesRow struct {
+	ID          uuid.UUID
+	Username    sql.NullString
+	DisplayName string
+	AvatarKey   sql.NullString
+}
+
+func (q *Queries) GetUserSummaries(ctx context.Context, ids []uuid.UUID) ([]GetUserSummariesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserSummaries, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserSummariesRow
+	for rows.Next() {
+		var i GetUserSummariesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.DisplayName,
+			&i.AvatarKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pinChirp = `-- name: PinChirp :execrows
+UPDATE users
+SET pinned_chirp_id = $1, updated_at = NOW()
+WHERE users.id = $2
+  AND EXISTS (
+    SELECT 1 FROM chirps
+    WHERE chirps.id = $1
+      AND chirps.user_id = users.id
+      AND chirps.deleted_at IS NULL
+      AND chirps.scheduled_for IS NULL
+  )
+`
+
+type PinChirpParams struct {
+	ChirpID uuid.NullUUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) PinChirp(ctx context.Context, arg PinChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pinChirp, arg.ChirpID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unpinChirp = `-- name: UnpinChirp :execrows
+UPDATE users
+SET pinned_chirp_id = NULL, updated_at = NOW()
+WHERE id = $1 AND pinned_chirp_id = $2
+`
+
+type UnpinChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.NullUUID
+}
+
+func (q *Queries) UnpinChirp(ctx context.Context, arg UnpinChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unpinChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $1, hashed_password = $2, updated_at = NOW()
@@ -114,6 +369,47 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 	return i, err
 }
 
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET username = $1, display_name = $2, bio = $3, avatar_media_id = $4, updated_at = NOW()
+WHERE id = $5
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, pinned_chirp_id, username, display_name, bio, avatar_media_id
+`
+
+type UpdateUserProfileParams struct {
+	Username      sql.NullString
+	DisplayName   string
+	Bio           string
+	AvatarMediaID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarMediaID,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.PinnedChirpID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
 const upgradeUser = `-- name: UpgradeUser :exec
 UPDATE users
 SET is_chirpy_red = true
@@ -0,0 +1,247 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	DefaultTimeout  = 5 * time.Second
+	DefaultMaxBytes = 512 << 10
+	maxRedirects    = 3
+	maxFieldLength  = 500
+)
+
+var (
+	ErrBlockedAddress = errors.New("address is not publicly routable")
+	ErrNotHTML        = errors.New("response is not an HTML page")
+)
+
+// Ranges that are not covered by the netip helpers but must never be
+// reached from the server.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Preview is the OpenGraph or Twitter card metadata of a page.
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Fetcher downloads pages and extracts their preview metadata. Connections
+// to private, loopback and other non-public addresses are refused at dial
+// time, so redirects and DNS answers cannot be used to reach internal
+// services.
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// New returns a Fetcher that only connects to public addresses.
+func New() *Fetcher {
+	return newFetcher(IsPublic)
+}
+
+// NewWithAddressPolicy returns a Fetcher that connects to any address
+// allowed by allow. Tests use it to reach servers on the loopback
+// interface.
+func NewWithAddressPolicy(allow func(netip.Addr) bool) *Fetcher {
+	return newFetcher(allow)
+}
+
+func newFetcher(allow func(netip.Addr) bool) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: DefaultTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(addrPort.Addr().Unmap()) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		// No proxy, so the dialer always sees the real destination
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   DefaultTimeout,
+		ResponseHeaderTimeout: DefaultTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   DefaultTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return errors.New("unsupported redirect scheme")
+				}
+				return nil
+			},
+		},
+		maxBytes: DefaultMaxBytes,
+	}
+}
+
+// IsPublic reports whether addr is a publicly routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch downloads at most a bounded prefix of the page at rawURL and parses
+// its metadata.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Preview{}, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Chirpy-LinkPreview/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Preview{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Preview{}, errors.New("unexpected status " + resp.Status)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return Preview{}, ErrNotHTML
+	}
+
+	preview := Parse(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL)
+	preview.URL = rawURL
+	return preview, nil
+}
+
+// Parse reads OpenGraph and Twitter card tags from the head of an HTML
+// document, falling back to the <title> element. Relative image URLs are
+// resolved against base.
+func Parse(r io.Reader, base *url.URL) Preview {
+	meta := map[string]string{}
+	title := ""
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		if tokenType == html.EndTagToken && token.Data == "head" {
+			break
+		}
+		if tokenType == html.StartTagToken && token.Data == "body" {
+			break
+		}
+		if tokenType == html.StartTagToken && token.Data == "title" && title == "" {
+			if tokenizer.Next() == html.TextToken {
+				title = strings.TrimSpace(tokenizer.Token().Data)
+			}
+			continue
+		}
+		if (tokenType == html.StartTagToken || tokenType == html.SelfClosingTagToken) && token.Data == "meta" {
+			key, content := "", ""
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "property", "name":
+					key = strings.ToLower(attr.Val)
+				case "content":
+					content = strings.TrimSpace(attr.Val)
+				}
+			}
+			if _, seen := meta[key]; key != "" && content != "" && !seen {
+				meta[key] = content
+			}
+		}
+	}
+
+	preview := Preview{
+		Title:       first(meta["og:title"], meta["twitter:title"], title),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    first(meta["og:site_name"]),
+	}
+	preview.ImageURL = resolveImage(first(meta["og:image"], meta["og:image:url"], meta["twitter:image"]), base)
+	return preview
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return truncate(value)
+		}
+	}
+	return ""
+}
+
+func truncate(s string) string {
+	if len(s) <= maxFieldLength {
+		return s
+	}
+	// Cut on a rune boundary
+	cut := maxFieldLength
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func resolveImage(raw string, base *url.URL) string {
+	if raw == "" {
+		return ""
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return ""
+	}
+	return ref.String()
+}
@@ -0,0 +1,221 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func allowAll(netip.Addr) bool { return true }
+
+func servePage(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func htmlPage(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func TestFetchRefusesLoopback(t *testing.T) {
+	server := servePage(t, htmlPage(`<title>Internal</title>`))
+
+	_, err := New().Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Fetch(%s) error = %v, want %v", server.URL, err, ErrBlockedAddress)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+	}
+	for _, tt := range tests {
+		got := IsPublic(netip.MustParseAddr(tt.addr))
+		if got != tt.want {
+			t.Errorf("IsPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := servePage(t, htmlPage(`<html><head>
+<meta property="og:title" content="Hello">
+<meta property="og:description" content="A page">
+<meta property="og:image" content="/img.png">
+</head><body></body></html>`))
+
+	preview, err := NewWithAddressPolicy(allowAll).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	want := Preview{
+		URL:         server.URL,
+		Title:       "Hello",
+		Description: "A page",
+		ImageURL:    server.URL + "/img.png",
+	}
+	if preview != want {
+		t.Errorf("Fetch = %+v, want %+v", preview, want)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	server := servePage(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+
+	_, err := NewWithAddressPolicy(allowAll).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrNotHTML) {
+		t.Fatalf("Fetch error = %v, want %v", err, ErrNotHTML)
+	}
+}
+
+func TestFetchStopsAtMaxBytes(t *testing.T) {
+	// The title comes after the size bound, so it must not be read
+	padding := strings.Repeat("<!-- padding -->", 200)
+	server := servePage(t, htmlPage(`<html><head>`+padding+`<meta property="og:title" content="Too far"></head></html>`))
+
+	fetcher := NewWithAddressPolicy(allowAll)
+	fetcher.maxBytes = 1024
+	preview, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview.Title != "" {
+		t.Errorf("Title = %q, want it cut off by the size bound", preview.Title)
+	}
+}
+
+func TestFetchTimesOut(t *testing.T) {
+	server := servePage(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	fetcher := NewWithAddressPolicy(allowAll)
+	fetcher.client.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	_, err := fetcher.Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch succeeded, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch took %v, want it to give up at the timeout", elapsed)
+	}
+}
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+	tests := []struct {
+		name string
+		html string
+		want Preview
+	}{
+		{
+			name: "OpenGraph tags",
+			html: `<head>
+<meta property="og:title" content=" Title ">
+<meta property="og:description" content="Description">
+<meta property="og:image" content="https://cdn.example.com/a.png">
+<meta property="og:site_name" content="Example">
+</head>`,
+			want: Preview{
+				Title:       "Title",
+				Description: "Description",
+				ImageURL:    "https://cdn.example.com/a.png",
+				SiteName:    "Example",
+			},
+		},
+		{
+			name: "relative image URL",
+			html: `<head><meta property="og:image" content="../images/b.png"></head>`,
+			want: Preview{ImageURL: "https://example.com/images/b.png"},
+		},
+		{
+			name: "non-HTTP image URL",
+			html: `<head><meta property="og:image" content="javascript:alert(1)"></head>`,
+			want: Preview{},
+		},
+		{
+			name: "Twitter card and description fallbacks",
+			html: `<head>
+<meta name="twitter:title" content="Card title">
+<meta name="description" content="Plain description">
+<meta name="twitter:image" content="/c.png">
+</head>`,
+			want: Preview{
+				Title:       "Card title",
+				Description: "Plain description",
+				ImageURL:    "https://example.com/c.png",
+			},
+		},
+		{
+			name: "title element fallback",
+			html: `<html><head><title> Page title </title></head></html>`,
+			want: Preview{Title: "Page title"},
+		},
+		{
+			name: "first tag wins",
+			html: `<head><meta property="og:title" content="One"><meta property="og:title" content="Two"></head>`,
+			want: Preview{Title: "One"},
+		},
+		{
+			name: "tags in the body are ignored",
+			html: `<head></head><body><meta property="og:title" content="Body"></body>`,
+			want: Preview{},
+		},
+		{
+			name: "no tags",
+			html: `<p>Just text</p>`,
+			want: Preview{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(strings.NewReader(tt.html), base)
+			if got != tt.want {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTruncatesLongFields(t *testing.T) {
+	long := strings.Repeat("é", maxFieldLength)
+	got := Parse(strings.NewReader(`<head><meta property="og:title" content="`+long+`"></head>`), nil)
+	if len(got.Title) > maxFieldLength {
+		t.Errorf("len(Title) = %d, want at most %d", len(got.Title), maxFieldLength)
+	}
+	if !strings.HasPrefix(long, got.Title) || len(got.Title)%2 != 0 {
+		t.Errorf("Title of %d bytes was not cut on a rune boundary", len(got.Title))
+	}
+}
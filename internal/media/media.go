@@ -0,0 +1,142 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// Larger images are rejected before they are decoded so a small upload
+	// cannot expand into an enormous bitmap.
+	MaxDimension = 8192
+	// Decoding needs several bytes per pixel, so the total is bounded too.
+	MaxPixels = 24_000_000
+	// Thumbnails fit in a square of this many pixels.
+	ThumbnailSize = 320
+	// Each thumbnail pixel averages at most this many samples per axis.
+	maxSamples = 4
+	// At most this many images are decoded at once.
+	maxConcurrentDecodes = 4
+)
+
+var decodeSlots = make(chan struct{}, maxConcurrentDecodes)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported media type")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+)
+
+var extensions = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpg",
+	"image/gif":  "gif",
+}
+
+// Image describes an uploaded image and its generated thumbnail.
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+
+	Thumbnail            []byte
+	ThumbnailContentType string
+}
+
+// Extension returns the file extension used to store files of contentType.
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
+
+// Process sniffs the type of an uploaded file from its contents rather than
+// trusting the client, reads its dimensions and renders a thumbnail.
+func Process(data []byte) (Image, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return Image{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupportedType
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension || config.Width*config.Height > MaxPixels {
+		return Image{}, ErrTooLarge
+	}
+
+	// Concurrent uploads wait for a slot rather than all holding a full
+	// bitmap in memory at once
+	decodeSlots <- struct{}{}
+	defer func() { <-decodeSlots }()
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupportedType
+	}
+
+	// JPEG thumbnails stay JPEG; everything else may be transparent
+	thumb := thumbnail(src)
+	buf := bytes.Buffer{}
+	thumbnailType := "image/png"
+	if contentType == "image/jpeg" {
+		thumbnailType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		ContentType:          contentType,
+		Width:                config.Width,
+		Height:               config.Height,
+		Thumbnail:            buf.Bytes(),
+		ThumbnailContentType: thumbnailType,
+	}, nil
+}
+
+// thumbnail scales src down to fit in ThumbnailSize, averaging a few samples
+// from the area each thumbnail pixel covers.
+func thumbnail(src image.Image) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= ThumbnailSize && height <= ThumbnailSize {
+		return src
+	}
+
+	longest := max(width, height)
+	thumbWidth := max(1, width*ThumbnailSize/longest)
+	thumbHeight := max(1, height*ThumbnailSize/longest)
+	dst := image.NewRGBA64(image.Rect(0, 0, thumbWidth, thumbHeight))
+
+	for y := 0; y < thumbHeight; y++ {
+		y0, y1 := y*height/thumbHeight, (y+1)*height/thumbHeight
+		for x := 0; x < thumbWidth; x++ {
+			x0, x1 := x*width/thumbWidth, (x+1)*width/thumbWidth
+			dst.SetRGBA64(x, y, average(src, bounds.Min, x0, x1, y0, y1))
+		}
+	}
+	return dst
+}
+
+func average(src image.Image, origin image.Point, x0, x1, y0, y1 int) color.RGBA64 {
+	stepX := max(1, (x1-x0)/maxSamples)
+	stepY := max(1, (y1-y0)/maxSamples)
+
+	var r, g, b, a, n uint64
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			sr, sg, sb, sa := src.At(origin.X+x, origin.Y+y).RGBA()
+			r, g, b, a = r+uint64(sr), g+uint64(sg), b+uint64(sb), a+uint64(sa)
+			n++
+		}
+	}
+	return color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)}
+}
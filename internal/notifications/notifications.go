@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+type Type string
+
+const (
+	TypeFollow    Type = "follow"
+	TypeLike      Type = "like"
+	TypeReply     Type = "reply"
+	TypeMention   Type = "mention"
+	TypeChirpyRed Type = "chirpy_red"
+)
+
+// Event is something that happened to a user. ActorID is the user who caused
+// it, if any, and ChirpID the chirp it is about, if any.
+type Event struct {
+	Type    Type
+	UserID  uuid.UUID
+	ActorID uuid.NullUUID
+	ChirpID uuid.NullUUID
+}
+
+// Record stores an event as a notification for the user it happened to and
+// returns it, or nil when no notification was needed. Users are never
+// notified about their own actions.
+func Record(ctx context.Context, q *database.Queries, event Event) (*database.Notification, error) {
+	if event.ActorID.Valid && event.ActorID.UUID == event.UserID {
+		return nil, nil
+	}
+
+	notification, err := q.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:  event.UserID,
+		Type:    string(event.Type),
+		ActorID: event.ActorID,
+		ChirpID: event.ChirpID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
@@ -0,0 +1,285 @@
+package profanity
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Action is what happens to a chirp that contains a blocked word.
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+)
+
+func ParseAction(s string) (Action, error) {
+	switch Action(s) {
+	case ActionMask, ActionReject, ActionFlag:
+		return Action(s), nil
+	}
+	return "", errors.New("unknown profanity action " + s)
+}
+
+// DefaultWords are blocked when no word list file is configured.
+var DefaultWords = []string{"kerfuffle", "sharbert", "fornax"}
+
+const mask = "****"
+
+// Symbols and digits commonly typed in place of letters.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
+
+var folder = cases.Fold()
+
+// Match is a blocked word found in a text, as byte offsets into it.
+type Match struct {
+	Start int
+	End   int
+	Word  string
+}
+
+// Filter finds blocked words in text. Its lists can be swapped while it is
+// in use.
+type Filter struct {
+	mu      sync.RWMutex
+	blocked map[string][]blockedWord
+	allowed map[string]bool
+}
+
+// blockedWord is a word from the block list with the length of each run of
+// repeated letters in its normalized form.
+type blockedWord struct {
+	word string
+	runs []int
+}
+
+func New(blocked, allowed []string) *Filter {
+	f := &Filter{}
+	f.Replace(blocked, allowed)
+	return f
+}
+
+// Replace swaps in new lists. Blocked words are compared after leetspeak
+// normalization, while allowed words exempt a token exactly as written
+// (ignoring case and accents).
+func (f *Filter) Replace(blocked, allowed []string) {
+	blockedSet := make(map[string][]blockedWord, len(blocked))
+	for _, word := range blocked {
+		if normalized, runs := normalizeRuns(word); normalized != "" {
+			blockedSet[normalized] = append(blockedSet[normalized], blockedWord{word: word, runs: runs})
+		}
+	}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, word := range allowed {
+		if folded := Fold(word); folded != "" {
+			allowedSet[folded] = true
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocked = blockedSet
+	f.allowed = allowedSet
+}
+
+// Find returns the blocked words in text in the order they appear.
+func (f *Filter) Find(text string) []Match {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	matches := []Match{}
+	for _, token := range tokenize(text) {
+		// Try the token as written, then without surrounding symbols, so
+		// both "$harbert" and "fornax!" are caught
+		for _, candidate := range candidates(text, token) {
+			if f.allowed[Fold(text[candidate.Start:candidate.End])] {
+				break
+			}
+			if word, ok := f.match(text[candidate.Start:candidate.End]); ok {
+				matches = append(matches, Match{Start: candidate.Start, End: candidate.End, Word: word})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// match looks token up in the block list. Repeated letters may be stretched
+// ("kerfuuuffle") but never shortened, so "as" does not match "ass".
+func (f *Filter) match(token string) (string, bool) {
+	normalized, runs := normalizeRuns(token)
+	for _, blocked := range f.blocked[normalized] {
+		if stretches(runs, blocked.runs) {
+			return blocked.word, true
+		}
+	}
+	return "", false
+}
+
+// stretches reports whether every run in runs is at least as long as the
+// matching run in word. Both come from the same normalized string.
+func stretches(runs, word []int) bool {
+	for i := range word {
+		if runs[i] < word[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Mask replaces every match in text with asterisks.
+func Mask(text string, matches []Match) string {
+	b := strings.Builder{}
+	last := 0
+	for _, match := range matches {
+		b.WriteString(text[last:match.Start])
+		b.WriteString(mask)
+		last = match.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Fold case-folds s and strips accents, so "FÖRNAX" and "fornax" compare equal.
+func Fold(s string) string {
+	decomposed := norm.NFKD.String(s)
+	stripped := strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, decomposed)
+	return folder.String(stripped)
+}
+
+// Normalize folds s, undoes leetspeak substitutions and collapses repeated
+// letters, so "K3rfuuuffle" and "kerfuffle" compare equal.
+func Normalize(s string) string {
+	normalized, _ := normalizeRuns(s)
+	return normalized
+}
+
+// normalizeRuns is Normalize that also returns how many times each letter of
+// the result was repeated.
+func normalizeRuns(s string) (string, []int) {
+	b := strings.Builder{}
+	runs := []int{}
+	var previous rune
+	for _, r := range Fold(s) {
+		if replacement, ok := leet[r]; ok {
+			r = replacement
+		}
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			continue
+		}
+		if r == previous {
+			runs[len(runs)-1]++
+			continue
+		}
+		b.WriteRune(r)
+		runs = append(runs, 1)
+		previous = r
+	}
+	return b.String(), runs
+}
+
+// LoadWords reads a word list with one word per line. Blank lines and lines
+// starting with # are ignored.
+func LoadWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+type span struct {
+	Start int
+	End   int
+}
+
+// tokenize splits text into runs of letters, digits and leetspeak symbols.
+func tokenize(text string) []span {
+	tokens := []span{}
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, span{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, span{Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+func candidates(text string, token span) []span {
+	trimmed := token
+	for trimmed.Start < trimmed.End {
+		r, size := utf8.DecodeRuneInString(text[trimmed.Start:])
+		if isLetterOrDigit(r) {
+			break
+		}
+		trimmed.Start += size
+	}
+	for trimmed.End > trimmed.Start {
+		r, size := utf8.DecodeLastRuneInString(text[:trimmed.End])
+		if isLetterOrDigit(r) {
+			break
+		}
+		trimmed.End -= size
+	}
+	if trimmed == token || trimmed.Start == trimmed.End {
+		return []span{token}
+	}
+	return []span{token, trimmed}
+}
+
+func isWordRune(r rune) bool {
+	_, isLeet := leet[r]
+	return isLetterOrDigit(r) || unicode.Is(unicode.Mn, r) || isLeet
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,117 @@
+package profanity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	filter := New([]string{"kerfuffle", "sharbert", "fornax", "ass", "boob"}, nil)
+
+	tests := []struct {
+		name string
+		text string
+		want []Match
+	}{
+		{
+			name: "clean text",
+			text: "This is a perfectly fine chirp",
+			want: []Match{},
+		},
+		{
+			name: "exact word",
+			text: "what a kerfuffle",
+			want: []Match{{Start: 7, End: 16, Word: "kerfuffle"}},
+		},
+		{
+			name: "case and accents",
+			text: "FÖRNAX",
+			want: []Match{{Start: 0, End: 7, Word: "fornax"}},
+		},
+		{
+			name: "trailing punctuation",
+			text: "fornax! again",
+			want: []Match{{Start: 0, End: 6, Word: "fornax"}},
+		},
+		{
+			name: "surrounding punctuation",
+			text: `"sharbert," she said`,
+			want: []Match{{Start: 1, End: 9, Word: "sharbert"}},
+		},
+		{
+			name: "leetspeak",
+			text: "$h4rb3rt and k3rfuffl3",
+			want: []Match{
+				{Start: 0, End: 8, Word: "sharbert"},
+				{Start: 13, End: 22, Word: "kerfuffle"},
+			},
+		},
+		{
+			name: "stretched letters",
+			text: "kerfuuuuffle and assss",
+			want: []Match{
+				{Start: 0, End: 12, Word: "kerfuffle"},
+				{Start: 17, End: 22, Word: "ass"},
+			},
+		},
+		{
+			name: "shorter word is not a match",
+			text: "as far as I know",
+			want: []Match{},
+		},
+		{
+			name: "name with a collapsed letter is not a match",
+			text: "Bob said hi",
+			want: []Match{},
+		},
+		{
+			name: "missing repeated letter is not a match",
+			text: "kerfufle",
+			want: []Match{},
+		},
+		{
+			name: "word inside a longer word",
+			text: "fornaxes",
+			want: []Match{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter.Find(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAllowlist(t *testing.T) {
+	// The constellation is fine, but a disguised spelling is not
+	filter := New([]string{"fornax"}, []string{"Fornax"})
+
+	tests := []struct {
+		text string
+		want []Match
+	}{
+		{text: "the Fornax cluster", want: []Match{}},
+		{text: "FORNAX!", want: []Match{}},
+		{text: "f0rnax", want: []Match{{Start: 0, End: 6, Word: "fornax"}}},
+		{text: "fornaaax", want: []Match{{Start: 0, End: 8, Word: "fornax"}}},
+	}
+	for _, tt := range tests {
+		got := filter.Find(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Find(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	filter := New(DefaultWords, nil)
+	text := "a kerfuffle, and fornax!"
+	got := Mask(text, filter.Find(text))
+	want := "a ****, and ****!"
+	if got != want {
+		t.Errorf("Mask(%q) = %q, want %q", text, got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rivo/uniseg"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+// Links count as a fixed number of characters however long they are
+const urlLengthWeight = 23
+
+type chirpTooLongError struct {
+	Length int
+	Limit  int
+}
+
+func (e *chirpTooLongError) Error() string {
+	return "Chirp is too long"
+}
+
+type chirpLengthResponse struct {
+	Error  string `json:"error"`
+	Length int    `json:"length"`
+	Limit  int    `json:"limit"`
+}
+
+// chirpLength counts a chirp body the way a reader sees it: one per
+// grapheme cluster, so an emoji or an accented letter counts once no matter
+// how many bytes or code points it takes.
+func chirpLength(body string) int {
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(body, -1) {
+		// Punctuation closing a sentence is not part of the link
+		end := loc[0] + len(strings.TrimRight(body[loc[0]:loc[1]], ".,;:!?)]}"))
+		length += uniseg.GraphemeClusterCount(body[last:loc[0]]) + urlLengthWeight
+		last = end
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
+
+// chirpLengthLimit returns the longest chirp the user may post. Chirpy Red
+// members get a higher limit.
+func (cfg *apiConfig) chirpLengthLimit(ctx context.Context, q *database.Queries, userID uuid.UUID) (int, error) {
+	user, err := q.GetUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.IsChirpyRed.Bool {
+		return cfg.chirpMaxLengthRed, nil
+	}
+	return cfg.chirpMaxLength, nil
+}
+
+func validateChirpBody(body string, limit int) error {
+	length := chirpLength(body)
+	if length > limit {
+		return &chirpTooLongError{Length: length, Limit: limit}
+	}
+	return nil
+}
+
+// respondWithInvalidChirp reports why a chirp could not be posted, or
+// fails with msg when the chirp itself was not the problem.
+func respondWithInvalidChirp(w http.ResponseWriter, err error, msg string) {
+	tooLong := &chirpTooLongError{}
+	switch {
+	case errors.As(err, &tooLong):
+		respondWithJSON(w, 400, chirpLengthResponse{
+			Error:  tooLong.Error(),
+			Length: tooLong.Length,
+			Limit:  tooLong.Limit,
+		})
+	case errors.Is(err, errParentNotFound), errors.Is(err, errQuotedChirpNotFound):
+		respondWithError(w, 404, err.Error())
+	case errors.Is(err, errProfanity):
+		respondWithError(w, 400, err.Error())
+	case errors.Is(err, errBlocked):
+		respondWithError(w, 403, err.Error())
+	default:
+		respondWithError(w, 500, msg)
+	}
+}
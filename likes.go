@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/notifications"
+)
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	// Make sure the chirp being liked exists
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	blocked, err := isBlockedBy(r.Context(), cfg.dbQueries, chirp.UserID, userID)
+	if err != nil {
+		respondWithError(w, 500, "Error liking chirp")
+		return
+	}
+	if blocked {
+		respondWithError(w, 403, errBlocked.Error())
+		return
+	}
+
+	var notification *database.Notification
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		liked, err := q.LikeChirp(r.Context(), database.LikeChirpParams{
+			ChirpID: chirpID,
+			UserID:  userID,
+		})
+		if err != nil || liked == 0 || !chirp.UserID.Valid {
+			return err
+		}
+		notification, err = notifications.Record(r.Context(), q, notifications.Event{
+			Type:    notifications.TypeLike,
+			UserID:  chirp.UserID.UUID,
+			ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+			ChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error liking chirp")
+		return
+	}
+	cfg.publishNotification(notification)
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	err = cfg.dbQueries.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+		ChirpID: chirpID,
+		UserID:  userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error unliking chirp")
+		return
+	}
+
+	w.WriteHeader(204)
+}
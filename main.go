@@ -1,25 +1,55 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/tiemouie01/chirpy/internal/auth"
+	"github.com/tiemouie01/chirpy/internal/broker"
 	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/linkpreview"
+	"github.com/tiemouie01/chirpy/internal/profanity"
+	"github.com/tiemouie01/chirpy/internal/storage"
 )
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	db             *sql.DB
 	dbQueries      *database.Queries
+	events         *broker.Broker
+	storage        storage.Storage
+	linkPreviews   *linkpreview.Fetcher
+	previewQueue   chan string
+	schedulerWake  chan struct{}
 	platform       string
 	jwtSecret      string
 	polkaApiKey    string
+
+	profanityFilter *profanity.Filter
+	profanityAction profanity.Action
+	profanityWords  []string
+
+	chirpMaxLength     int
+	chirpMaxLengthRed  int
+	chirpEditWindow    time.Duration
+	chirpRestoreWindow time.Duration
+	chirpRetention     time.Duration
 }
 
 type jsonError struct {
@@ -41,10 +71,48 @@ func main() {
 
 	apiCfg := apiConfig{
 		fileserverHits: atomic.Int32{},
+		db:             db,
 		dbQueries:      dbQueries,
+		events:         broker.New(),
+		storage:        storage.NewLocal(filepath.Join(filepathRoot, "media"), "/app/media"),
+		schedulerWake:  make(chan struct{}, 1),
+		linkPreviews:   linkpreview.New(),
+		previewQueue:   make(chan string, linkPreviewQueueSize),
 		platform:       os.Getenv("PLATFORM"),
 		jwtSecret:      os.Getenv("JWT_SECRET"),
 		polkaApiKey:    os.Getenv("POLKA_KEY"),
+
+		chirpMaxLength:     intFromEnv("CHIRP_MAX_LENGTH", 140),
+		chirpMaxLengthRed:  intFromEnv("CHIRP_MAX_LENGTH_RED", 280),
+		chirpEditWindow:    durationFromEnv("CHIRP_EDIT_WINDOW", time.Hour),
+		chirpRestoreWindow: durationFromEnv("CHIRP_RESTORE_WINDOW", 7*24*time.Hour),
+		chirpRetention:     durationFromEnv("CHIRP_RETENTION", 30*24*time.Hour),
+	}
+	// The built-in word list can be replaced by a file with one word per line
+	apiCfg.profanityWords = profanity.DefaultWords
+	if path := os.Getenv("PROFANITY_WORDS_FILE"); path != "" {
+		apiCfg.profanityWords, err = profanity.LoadWords(path)
+		if err != nil {
+			log.Fatalf("Failed to load profanity words: %v", err)
+		}
+	}
+	apiCfg.profanityAction = profanity.ActionMask
+	if action := os.Getenv("PROFANITY_ACTION"); action != "" {
+		apiCfg.profanityAction, err = profanity.ParseAction(action)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	apiCfg.profanityFilter = profanity.New(apiCfg.profanityWords, nil)
+	err = apiCfg.reloadProfanityFilter(context.Background())
+	if err != nil {
+		log.Printf("Error loading profanity words from the database: %v\n", err)
+	}
+
+	// A chirp cannot be restored once it has been purged
+	if apiCfg.chirpRestoreWindow > apiCfg.chirpRetention {
+		log.Printf("CHIRP_RESTORE_WINDOW exceeds CHIRP_RETENTION, using %s\n", apiCfg.chirpRetention)
+		apiCfg.chirpRestoreWindow = apiCfg.chirpRetention
 	}
 
 	mux := http.NewServeMux()
@@ -52,15 +120,80 @@ func main() {
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
+	mux.HandleFunc("GET /admin/profanity", apiCfg.handlerGetProfanityLists)
+	mux.HandleFunc("POST /admin/profanity/words", apiCfg.handlerAddBlockedWord)
+	mux.HandleFunc("DELETE /admin/profanity/words/{word}", apiCfg.handlerRemoveBlockedWord)
+	mux.HandleFunc("POST /admin/profanity/allowed", apiCfg.handlerAddAllowedWord)
+	mux.HandleFunc("DELETE /admin/profanity/allowed/{word}", apiCfg.handlerRemoveAllowedWord)
+	mux.HandleFunc("GET /admin/profanity/flags", apiCfg.handlerGetFlaggedChirps)
+	mux.HandleFunc("DELETE /admin/profanity/flags/{chirpID}", apiCfg.handlerDismissChirpFlag)
+	mux.HandleFunc("GET /admin/reports", apiCfg.handlerGetReports)
+	mux.HandleFunc("POST /admin/reports/{reportID}/dismiss", apiCfg.handlerDismissReport)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/hide", apiCfg.handlerHideChirp)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/unhide", apiCfg.handlerUnhideChirp)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/remove", apiCfg.handlerRemoveChirp)
+	mux.HandleFunc("GET /admin/moderation/log", apiCfg.handlerGetModerationLog)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetAllChirps)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerSearchChirps)
+	mux.HandleFunc("GET /api/chirps/stream", apiCfg.handlerStreamChirps)
+	mux.HandleFunc("GET /api/chirps/scheduled", apiCfg.handlerGetScheduledChirps)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirp)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerCreateChirp)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.handlerRestoreChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/report", apiCfg.handlerReportChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handlerVoteInPoll)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/pin", apiCfg.handlerPinChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/pin", apiCfg.handlerUnpinChirp)
+	mux.HandleFunc("GET /api/bookmarks/collections", apiCfg.handlerGetBookmarkCollections)
+	mux.HandleFunc("POST /api/bookmarks/collections", apiCfg.handlerCreateBookmarkCollection)
+	mux.HandleFunc("PUT /api/bookmarks/collections/{collectionID}", apiCfg.handlerRenameBookmarkCollection)
+	mux.HandleFunc("DELETE /api/bookmarks/collections/{collectionID}", apiCfg.handlerDeleteBookmarkCollection)
+	mux.HandleFunc("GET /api/bookmarks/collections/{collectionID}/chirps", apiCfg.handlerGetBookmarkedChirps)
+	mux.HandleFunc("POST /api/bookmarks/collections/{collectionID}/chirps", apiCfg.handlerAddBookmark)
+	mux.HandleFunc("DELETE /api/bookmarks/collections/{collectionID}/chirps/{chirpID}", apiCfg.handlerRemoveBookmark)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}/schedule", apiCfg.handlerRescheduleChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/schedule", apiCfg.handlerCancelScheduledChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/history", apiCfg.handlerGetChirpHistory)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/replies", apiCfg.handlerGetChirpReplies)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetChirpThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handlerRechirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handlerUndoRechirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/likes", apiCfg.handlerLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", apiCfg.handlerUnlikeChirp)
+	mux.HandleFunc("POST /api/media", apiCfg.handlerUploadMedia)
 	mux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerUpdateUser)
+	mux.HandleFunc("PATCH /api/users/me", apiCfg.handlerUpdateProfile)
+	mux.HandleFunc("GET /api/users/{username}", apiCfg.handlerGetProfile)
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLoginUser)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefreshToken)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevokeToken)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollowUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollowUser)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerGetFollowing)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handlerBlockUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handlerUnblockUser)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handlerMuteUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handlerUnmuteUser)
+	mux.HandleFunc("GET /api/blocks", apiCfg.handlerGetBlockedUsers)
+	mux.HandleFunc("GET /api/mutes", apiCfg.handlerGetMutedUsers)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handlerGetDrafts)
+	mux.HandleFunc("POST /api/drafts", apiCfg.handlerCreateDraft)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handlerGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handlerUpdateDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handlerDeleteDraft)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlerPublishDraft)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerGetTimeline)
+	mux.HandleFunc("GET /api/mentions", apiCfg.handlerGetMentions)
+	mux.HandleFunc("GET /api/notifications", apiCfg.handlerGetNotifications)
+	mux.HandleFunc("POST /api/notifications/read", apiCfg.handlerMarkNotificationsRead)
+	mux.HandleFunc("GET /api/notifications/unread_count", apiCfg.handlerGetUnreadNotificationCount)
+	mux.HandleFunc("GET /api/ws", apiCfg.handlerWebSocket)
+	mux.HandleFunc("GET /api/hashtags/trending", apiCfg.handlerGetTrendingHashtags)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerGetHashtagChirps)
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerUpgradeUser)
 
 	srv := &http.Server{
@@ -68,8 +201,72 @@ func main() {
 		Handler: mux,
 	}
 
-	log.Printf("Serving files from %s on port: %s\n", filepathRoot, port)
-	log.Fatal(srv.ListenAndServe())
+	// Ending the event streams lets Shutdown finish waiting on them
+	srv.RegisterOnShutdown(apiCfg.events.Close)
+
+	go func() {
+		log.Printf("Serving files from %s on port: %s\n", filepathRoot, port)
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go apiCfg.runChirpPurge(ctx)
+	go apiCfg.runScheduler(ctx)
+	for range linkPreviewWorkers {
+		go apiCfg.runLinkPreviewWorker(ctx)
+	}
+	<-ctx.Done()
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = srv.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Printf("Error shutting down: %v\n", err)
+	}
+}
+
+// Configured durations are passed to queries as int32 seconds, so longer
+// ones would wrap around to a negative interval.
+const maxEnvDuration = math.MaxInt32 * time.Second
+
+// durationFromEnv reads an optional duration such as "15m" from the
+// environment, falling back to def when it is unset or invalid and capping
+// it at maxEnvDuration.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %s\n", name, value, def)
+		return def
+	}
+	if duration > maxEnvDuration {
+		log.Printf("%s %q is too long, using %s\n", name, value, maxEnvDuration)
+		return maxEnvDuration
+	}
+	return duration
+}
+
+// intFromEnv reads an optional positive integer from the environment,
+// falling back to def when it is unset or invalid.
+func intFromEnv(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %d\n", name, value, def)
+		return def
+	}
+	return n
 }
 
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
@@ -116,3 +313,65 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	w.Write(response)
 }
+
+// withTx runs fn inside a database transaction, committing when fn succeeds
+// and rolling back otherwise.
+func (cfg *apiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(cfg.dbQueries.WithTx(tx))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// authenticateUser returns the ID of the user holding the request's bearer token.
+func (cfg *apiConfig) authenticateUser(r *http.Request) (uuid.UUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return auth.ValidateJWT(token, cfg.jwtSecret)
+}
+
+var errNotAdmin = errors.New("You must be an admin to access this resource.")
+
+// authenticateAdmin returns the ID of the admin holding the request's bearer
+// token. Admins are granted by setting users.is_admin in the database.
+func (cfg *apiConfig) authenticateAdmin(r *http.Request) (uuid.UUID, error) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	user, err := cfg.dbQueries.GetUser(r.Context(), userID)
+	if err != nil || !user.IsAdmin {
+		return uuid.Nil, errNotAdmin
+	}
+	return userID, nil
+}
+
+func respondWithAdminError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotAdmin) {
+		respondWithError(w, 403, err.Error())
+		return
+	}
+	respondWithError(w, 401, "You are not authorized to access this resource.")
+}
+
+// viewerFromRequest identifies the optional viewer of a public endpoint.
+// Requests without an Authorization header are treated as anonymous.
+func (cfg *apiConfig) viewerFromRequest(r *http.Request) (uuid.NullUUID, error) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, nil
+	}
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: userID, Valid: true}, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/media"
+)
+
+const (
+	maxMediaSize        = 5 << 20
+	maxChirpAttachments = 4
+	// Uploads that never made it into a chirp are removed after this long
+	abandonedMediaAge = 24 * time.Hour
+)
+
+var errMediaNotFound = errors.New("Media not found or already attached")
+
+type Attachment struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+}
+
+func (cfg *apiConfig) formatAttachment(m database.Media) Attachment {
+	return Attachment{
+		ID:           m.ID.String(),
+		URL:          cfg.storage.URL(m.StorageKey),
+		ThumbnailURL: cfg.storage.URL(m.ThumbnailKey),
+		ContentType:  m.ContentType,
+		Width:        m.Width,
+		Height:       m.Height,
+	}
+}
+
+func (cfg *apiConfig) handlerUploadMedia(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	// Leave some room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaSize+(1<<20))
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		maxBytesErr := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, 413, "File is too large")
+			return
+		}
+		respondWithError(w, 400, "Missing file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMediaSize+1))
+	if err != nil {
+		respondWithError(w, 400, "Error reading file")
+		return
+	}
+	if len(data) > maxMediaSize {
+		respondWithError(w, 413, "File is too large")
+		return
+	}
+
+	img, err := media.Process(data)
+	switch {
+	case errors.Is(err, media.ErrUnsupportedType):
+		respondWithError(w, 415, "Only PNG, JPEG and GIF images are supported")
+		return
+	case errors.Is(err, media.ErrTooLarge):
+		respondWithError(w, 400, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, 500, "Error processing image")
+		return
+	}
+
+	id := uuid.New()
+	storageKey := id.String() + "." + media.Extension(img.ContentType)
+	thumbnailKey := id.String() + "_thumb." + media.Extension(img.ThumbnailContentType)
+	err = cfg.storage.Save(r.Context(), storageKey, bytes.NewReader(data))
+	if err == nil {
+		err = cfg.storage.Save(r.Context(), thumbnailKey, bytes.NewReader(img.Thumbnail))
+	}
+	if err != nil {
+		cfg.deleteMediaFiles(r.Context(), []database.Media{{StorageKey: storageKey, ThumbnailKey: thumbnailKey}})
+		respondWithError(w, 500, "Error saving media")
+		return
+	}
+	row, err := cfg.dbQueries.CreateMedia(r.Context(), database.CreateMediaParams{
+		ID:           id,
+		UserID:       userID,
+		ContentType:  img.ContentType,
+		SizeBytes:    int64(len(data)),
+		Width:        int32(img.Width),
+		Height:       int32(img.Height),
+		StorageKey:   storageKey,
+		ThumbnailKey: thumbnailKey,
+	})
+	if err != nil {
+		// A failed insert returns an empty row, so delete by the keys we saved
+		cfg.deleteMediaFiles(r.Context(), []database.Media{{StorageKey: storageKey, ThumbnailKey: thumbnailKey}})
+		respondWithError(w, 500, "Error saving media")
+		return
+	}
+
+	respondWithJSON(w, 201, cfg.formatAttachment(row))
+}
+
+func validateMediaIDs(ids []uuid.UUID) error {
+	if len(ids) > maxChirpAttachments {
+		return errors.New("A chirp can have at most 4 attachments")
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errors.New("Duplicate media ID")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// attachMedia attaches the author's uploads to a chirp in the order given.
+// Each upload can only ever belong to one chirp.
+func attachMedia(ctx context.Context, q *database.Queries, chirp database.Chirp, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	attached, err := q.AttachMedia(ctx, database.AttachMediaParams{
+		ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		Ids:     ids,
+		UserID:  chirp.UserID.UUID,
+	})
+	if err != nil {
+		return err
+	}
+	if attached != int64(len(ids)) {
+		return errMediaNotFound
+	}
+	return nil
+}
+
+// deleteMediaFiles removes stored files once their rows are gone. Failures
+// are only logged, since the rows no longer reference the files.
+func (cfg *apiConfig) deleteMediaFiles(ctx context.Context, removed []database.Media) {
+	for _, m := range removed {
+		for _, key := range []string{m.StorageKey, m.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			err := cfg.storage.Delete(ctx, key)
+			if err != nil {
+				log.Printf("Error deleting media file %s: %v\n", key, err)
+			}
+		}
+	}
+}
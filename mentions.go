@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/notifications"
+)
+
+// A mention is @ followed by a username. It must not be glued to a preceding
+// word so email addresses in a chirp body are not treated as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([A-Za-z0-9_]+)`)
+
+type Mention struct {
+	UserID uuid.UUID `json:"user_id"`
+	Start  int32     `json:"start"`
+	End    int32     `json:"end"`
+}
+
+type mentionMatch struct {
+	Handle string
+	Start  int
+	End    int
+}
+
+// extractMentions finds mentions in a chirp body. Offsets are byte offsets
+// into the body, covering the leading @.
+func extractMentions(body string) []mentionMatch {
+	matches := []mentionMatch{}
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(body, -1) {
+		handle := body[loc[2]:loc[3]]
+		matches = append(matches, mentionMatch{
+			Handle: strings.ToLower(handle),
+			Start:  loc[2] - 1,
+			End:    loc[2] + len(handle),
+		})
+	}
+	return matches
+}
+
+// resolveMentions maps each handle to the user with that username. Handles
+// are never matched against email addresses, so a mention cannot be used to
+// find out who has an account.
+func resolveMentions(ctx context.Context, q *database.Queries, matches []mentionMatch) (map[string]uuid.UUID, error) {
+	resolved := map[string]uuid.UUID{}
+	if len(matches) == 0 {
+		return resolved, nil
+	}
+
+	handles := make([]string, len(matches))
+	for i, match := range matches {
+		handles[i] = match.Handle
+	}
+	users, err := q.FindUsersByUsername(ctx, handles)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		resolved[user.Handle] = user.ID
+	}
+	return resolved, nil
+}
+
+// dropBlockedMentions removes the users who have blocked the author, so the
+// handle stays plain text and they are not notified.
+func dropBlockedMentions(ctx context.Context, q *database.Queries, authorID uuid.NullUUID, resolved map[string]uuid.UUID) error {
+	if !authorID.Valid || len(resolved) == 0 {
+		return nil
+	}
+	userIDs := make([]uuid.UUID, 0, len(resolved))
+	for _, userID := range resolved {
+		userIDs = append(userIDs, userID)
+	}
+	blockers, err := q.ListBlockersAmong(ctx, database.ListBlockersAmongParams{
+		BlockedID: authorID.UUID,
+		UserIds:   userIDs,
+	})
+	if err != nil {
+		return err
+	}
+	blocked := make(map[uuid.UUID]bool, len(blockers))
+	for _, blocker := range blockers {
+		blocked[blocker] = true
+	}
+	for handle, userID := range resolved {
+		if blocked[userID] {
+			delete(resolved, handle)
+		}
+	}
+	return nil
+}
+
+// mentionUsers stores the mentions in a chirp and notifies the mentioned
+// users, returning the notifications it created. Users in alreadyNotified,
+// such as those mentioned before an edit, are not notified again.
+func mentionUsers(ctx context.Context, q *database.Queries, chirp database.Chirp, alreadyNotified []uuid.UUID) ([]database.Notification, error) {
+	matches := extractMentions(chirp.Body)
+	resolved, err := resolveMentions(ctx, q, matches)
+	if err != nil {
+		return nil, err
+	}
+	err = dropBlockedMentions(ctx, q, chirp.UserID, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	created := []database.Notification{}
+	notified := map[uuid.UUID]bool{}
+	for _, userID := range alreadyNotified {
+		notified[userID] = true
+	}
+	for _, match := range matches {
+		userID, ok := resolved[match.Handle]
+		if !ok {
+			continue
+		}
+		err = q.CreateMention(ctx, database.CreateMentionParams{
+			ChirpID:     chirp.ID,
+			UserID:      userID,
+			StartOffset: int32(match.Start),
+			EndOffset:   int32(match.End),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Mentioning someone twice in one chirp only notifies them once
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		notification, err := notifications.Record(ctx, q, notifications.Event{
+			Type:    notifications.TypeMention,
+			UserID:  userID,
+			ActorID: chirp.UserID,
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if notification != nil {
+			created = append(created, *notification)
+		}
+	}
+	return created, nil
+}
+
+func (cfg *apiConfig) handlerGetMentions(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	chirps, err := cfg.dbQueries.ListMentioningChirps(r.Context(), database.ListMentioningChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting mentions")
+		return
+	}
+
+	page, err := cfg.newChirpPage(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps, limit)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting mentions")
+		return
+	}
+
+	respondWithJSON(w, 200, page)
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	maxReportReasonLength   = 500
+	maxModerationNoteLength = 1000
+
+	reportOpen      = "open"
+	reportResolved  = "resolved"
+	reportDismissed = "dismissed"
+
+	moderationHide    = "hide"
+	moderationUnhide  = "unhide"
+	moderationRemove  = "remove"
+	moderationDismiss = "dismiss"
+)
+
+var errReportNotFound = errors.New("Report not found")
+
+type Report struct {
+	ID         string  `json:"id"`
+	CreatedAt  string  `json:"created_at"`
+	ChirpID    *string `json:"chirp_id"`
+	ReporterID string  `json:"reporter_id"`
+	Reason     string  `json:"reason"`
+	Status     string  `json:"status"`
+	ResolvedAt *string `json:"resolved_at"`
+	ResolvedBy *string `json:"resolved_by"`
+	Chirp      *Chirp  `json:"chirp,omitempty"`
+}
+
+type reportPage struct {
+	Reports    []Report `json:"reports"`
+	NextCursor *string  `json:"next_cursor"`
+}
+
+type ModerationAction struct {
+	ID          string   `json:"id"`
+	CreatedAt   string   `json:"created_at"`
+	ModeratorID *string  `json:"moderator_id"`
+	Action      string   `json:"action"`
+	ChirpID     *string  `json:"chirp_id"`
+	ChirpUserID *string  `json:"chirp_user_id"`
+	ChirpBody   string   `json:"chirp_body"`
+	ReportIDs   []string `json:"report_ids"`
+	Note        string   `json:"note"`
+}
+
+type moderationActionPage struct {
+	Actions    []ModerationAction `json:"actions"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+func formatReport(report database.Report) Report {
+	formatted := Report{
+		ID:         report.ID.String(),
+		CreatedAt:  report.CreatedAt.String(),
+		ChirpID:    nullUUIDString(report.ChirpID),
+		ReporterID: report.ReporterID.String(),
+		Reason:     report.Reason,
+		Status:     report.Status,
+		ResolvedBy: nullUUIDString(report.ResolvedBy),
+	}
+	if report.ResolvedAt.Valid {
+		resolvedAt := report.ResolvedAt.Time.String()
+		formatted.ResolvedAt = &resolvedAt
+	}
+	return formatted
+}
+
+func formatModerationAction(action database.ModerationAction) ModerationAction {
+	reportIDs := make([]string, len(action.ReportIds))
+	for i, id := range action.ReportIds {
+		reportIDs[i] = id.String()
+	}
+	return ModerationAction{
+		ID:          action.ID.String(),
+		CreatedAt:   action.CreatedAt.String(),
+		ModeratorID: nullUUIDString(action.ModeratorID),
+		Action:      action.Action,
+		ChirpID:     nullUUIDString(action.ChirpID),
+		ChirpUserID: nullUUIDString(action.ChirpUserID),
+		ChirpBody:   action.ChirpBody,
+		ReportIDs:   reportIDs,
+		Note:        action.Note,
+	}
+}
+
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason string `json:"reason"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to report this chirp.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+	reason := strings.TrimSpace(params.Reason)
+	if reason == "" {
+		respondWithError(w, 400, "A reason is required")
+		return
+	}
+	if len(reason) > maxReportReasonLength {
+		respondWithError(w, 400, "Reason is too long")
+		return
+	}
+
+	_, err = cfg.dbQueries.GetChirp(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	report, err := cfg.dbQueries.CreateReport(r.Context(), database.CreateReportParams{
+		ChirpID:    uuid.NullUUID{UUID: id, Valid: true},
+		ReporterID: userID,
+		Reason:     reason,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 409, "You have already reported this chirp")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error reporting chirp")
+		return
+	}
+
+	respondWithJSON(w, 201, formatReport(report))
+}
+
+func (cfg *apiConfig) handlerGetReports(w http.ResponseWriter, r *http.Request) {
+	adminID, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = reportOpen
+	case reportOpen, reportResolved, reportDismissed:
+	default:
+		respondWithError(w, 400, "status must be open, resolved or dismissed")
+		return
+	}
+
+	// The queue is worked oldest first
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	reports, err := cfg.dbQueries.ListReports(r.Context(), database.ListReportsParams{
+		Status:          status,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting reports")
+		return
+	}
+	reports, next := nextCursor(reports, limit, func(report database.Report) (time.Time, uuid.UUID) {
+		return report.CreatedAt, report.ID
+	})
+
+	chirpIDs := []uuid.UUID{}
+	for _, report := range reports {
+		if report.ChirpID.Valid {
+			chirpIDs = append(chirpIDs, report.ChirpID.UUID)
+		}
+	}
+	chirps, err := cfg.dbQueries.GetChirpsByIDs(r.Context(), chirpIDs)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting reports")
+		return
+	}
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: adminID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting reports")
+		return
+	}
+	chirpsByID := make(map[uuid.UUID]*Chirp, len(chirps))
+	for i, chirp := range chirps {
+		chirpsByID[chirp.ID] = &formattedChirps[i]
+	}
+
+	formattedReports := make([]Report, len(reports))
+	for i, report := range reports {
+		formattedReports[i] = formatReport(report)
+		if report.ChirpID.Valid {
+			formattedReports[i].Chirp = chirpsByID[report.ChirpID.UUID]
+		}
+	}
+
+	respondWithJSON(w, 200, reportPage{Reports: formattedReports, NextCursor: next})
+}
+
+func decodeModerationNote(r *http.Request) (string, error) {
+	type parameters struct {
+		Note string `json:"note"`
+	}
+
+	// The note is optional, so an empty body is fine
+	params := parameters{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", errors.New("Error decoding JSON")
+	}
+	note := strings.TrimSpace(params.Note)
+	if len(note) > maxModerationNoteLength {
+		return "", errors.New("Note is too long")
+	}
+	return note, nil
+}
+
+func (cfg *apiConfig) handlerDismissReport(w http.ResponseWriter, r *http.Request) {
+	adminID, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid report ID")
+		return
+	}
+	note, err := decodeModerationNote(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	var action database.ModerationAction
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		report, err := q.DismissReport(r.Context(), database.DismissReportParams{
+			ModeratorID: uuid.NullUUID{UUID: adminID, Valid: true},
+			ID:          id,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errReportNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		params := database.CreateModerationActionParams{
+			ModeratorID: uuid.NullUUID{UUID: adminID, Valid: true},
+			Action:      moderationDismiss,
+			ChirpID:     report.ChirpID,
+			ReportIds:   []uuid.UUID{report.ID},
+			Note:        note,
+		}
+		if report.ChirpID.Valid {
+			chirp, err := q.GetChirpForModeration(r.Context(), report.ChirpID.UUID)
+			if err == nil {
+				params.ChirpUserID = chirp.UserID
+				params.ChirpBody = chirp.Body
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		action, err = q.CreateModerationAction(r.Context(), params)
+		return err
+	})
+	if errors.Is(err, errReportNotFound) {
+		respondWithError(w, 404, "Open report not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error dismissing report")
+		return
+	}
+
+	respondWithJSON(w, 200, formatModerationAction(action))
+}
+
+// moderateChirp runs a moderator action against a chirp inside a transaction.
+// Any open reports on the chirp are resolved, and the decision is recorded
+// with a copy of the chirp so the audit trail outlives it.
+func (cfg *apiConfig) moderateChirp(ctx context.Context, adminID, chirpID uuid.UUID, kind, note string, apply func(q *database.Queries, chirp database.Chirp) error) (database.Chirp, database.ModerationAction, error) {
+	var chirp database.Chirp
+	var action database.ModerationAction
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		chirp, err = q.GetChirpForModeration(ctx, chirpID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errChirpNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Reports are resolved first, as removing the chirp detaches them
+		reportIDs, err := q.ResolveChirpReports(ctx, database.ResolveChirpReportsParams{
+			ModeratorID: uuid.NullUUID{UUID: adminID, Valid: true},
+			ChirpID:     uuid.NullUUID{UUID: chirpID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if reportIDs == nil {
+			reportIDs = []uuid.UUID{}
+		}
+
+		err = apply(q, chirp)
+		if err != nil {
+			return err
+		}
+
+		action, err = q.CreateModerationAction(ctx, database.CreateModerationActionParams{
+			ModeratorID: uuid.NullUUID{UUID: adminID, Valid: true},
+			Action:      kind,
+			ChirpID:     uuid.NullUUID{UUID: chirpID, Valid: true},
+			ChirpUserID: chirp.UserID,
+			ChirpBody:   chirp.Body,
+			ReportIds:   reportIDs,
+			Note:        note,
+		})
+		return err
+	})
+	return chirp, action, err
+}
+
+func (cfg *apiConfig) moderationRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, string, bool) {
+	adminID, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+	note, err := decodeModerationNote(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return uuid.Nil, uuid.Nil, "", false
+	}
+	return adminID, chirpID, note, true
+}
+
+// handlerHideChirp takes a chirp down without deleting it. The author cannot
+// restore a hidden chirp, but a moderator can unhide it until the purge job
+// removes it with the rest of the deleted chirps.
+func (cfg *apiConfig) handlerHideChirp(w http.ResponseWriter, r *http.Request) {
+	adminID, chirpID, note, ok := cfg.moderationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, action, err := cfg.moderateChirp(r.Context(), adminID, chirpID, moderationHide, note, func(q *database.Queries, chirp database.Chirp) error {
+		_, err := q.HideChirp(r.Context(), chirp.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errChirpNotFound
+		}
+		return err
+	})
+	if errors.Is(err, errChirpNotFound) {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error hiding chirp")
+		return
+	}
+	if chirp.UserID.Valid {
+		cfg.publishChirpDeleted(chirp.ID, chirp.UserID.UUID)
+	}
+
+	respondWithJSON(w, 200, formatModerationAction(action))
+}
+
+func (cfg *apiConfig) handlerUnhideChirp(w http.ResponseWriter, r *http.Request) {
+	adminID, chirpID, note, ok := cfg.moderationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var restored database.Chirp
+	_, action, err := cfg.moderateChirp(r.Context(), adminID, chirpID, moderationUnhide, note, func(q *database.Queries, chirp database.Chirp) error {
+		var err error
+		restored, err = q.UnhideChirp(r.Context(), chirp.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errChirpNotFound
+		}
+		return err
+	})
+	if errors.Is(err, errChirpNotFound) {
+		respondWithError(w, 404, "Hidden chirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error unhiding chirp")
+		return
+	}
+
+	cfg.publishChirpCreated(r.Context(), restored)
+
+	respondWithJSON(w, 200, formatModerationAction(action))
+}
+
+// handlerRemoveChirp deletes a chirp for good. Like a purged chirp, it is
+// kept as a tombstone when other chirps reply to it.
+func (cfg *apiConfig) handlerRemoveChirp(w http.ResponseWriter, r *http.Request) {
+	adminID, chirpID, note, ok := cfg.moderationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var removed []database.Media
+	chirp, action, err := cfg.moderateChirp(r.Context(), adminID, chirpID, moderationRemove, note, func(q *database.Queries, chirp database.Chirp) error {
+		var err error
+		removed, err = q.DeleteChirpMedia(r.Context(), uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+		err = q.DeleteChirpRevisions(r.Context(), chirp.ID)
+		if err != nil {
+			return err
+		}
+		_, err = q.TombstoneChirp(r.Context(), chirp.ID)
+		if err != nil {
+			return err
+		}
+		_, err = q.DeleteChirp(r.Context(), chirp.ID)
+		return err
+	})
+	if errors.Is(err, errChirpNotFound) {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error removing chirp")
+		return
+	}
+	cfg.deleteMediaFiles(r.Context(), removed)
+	if chirp.UserID.Valid && !chirp.DeletedAt.Valid {
+		cfg.publishChirpDeleted(chirp.ID, chirp.UserID.UUID)
+	}
+
+	respondWithJSON(w, 200, formatModerationAction(action))
+}
+
+func (cfg *apiConfig) handlerGetModerationLog(w http.ResponseWriter, r *http.Request) {
+	_, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	actions, err := cfg.dbQueries.ListModerationActions(r.Context(), database.ListModerationActionsParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting moderation log")
+		return
+	}
+	actions, next := nextCursor(actions, limit, func(action database.ModerationAction) (time.Time, uuid.UUID) {
+		return action.CreatedAt, action.ID
+	})
+
+	formattedActions := make([]ModerationAction, len(actions))
+	for i, action := range actions {
+		formattedActions[i] = formatModerationAction(action)
+	}
+
+	respondWithJSON(w, 200, moderationActionPage{Actions: formattedActions, NextCursor: next})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/broker"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const eventNotificationCreated = "notification.created"
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Type      string     `json:"type"`
+	ActorID   *uuid.UUID `json:"actor_id"`
+	ChirpID   *uuid.UUID `json:"chirp_id"`
+	Read      bool       `json:"read"`
+}
+
+type notificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    *string        `json:"next_cursor"`
+}
+
+func formatNotification(notification database.Notification) Notification {
+	formattedNotification := Notification{
+		ID:        notification.ID,
+		CreatedAt: notification.CreatedAt,
+		Type:      notification.Type,
+		Read:      notification.ReadAt.Valid,
+	}
+	if notification.ActorID.Valid {
+		formattedNotification.ActorID = &notification.ActorID.UUID
+	}
+	if notification.ChirpID.Valid {
+		formattedNotification.ChirpID = &notification.ChirpID.UUID
+	}
+	return formattedNotification
+}
+
+func notificationsTopic(userID uuid.UUID) string {
+	return "notifications/" + userID.String()
+}
+
+// publishNotification pushes a committed notification to its recipient's
+// realtime connections. A nil notification is ignored.
+func (cfg *apiConfig) publishNotification(notification *database.Notification) {
+	if notification == nil {
+		return
+	}
+	cfg.events.Publish(broker.Message{
+		Topic: notificationsTopic(notification.UserID),
+		Event: eventNotificationCreated,
+		ID:    notification.ID.String(),
+		Data:  formatNotification(*notification),
+	})
+}
+
+func (cfg *apiConfig) handlerGetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListNotifications(r.Context(), database.ListNotificationsParams{
+		UserID:          userID,
+		UnreadOnly:      r.URL.Query().Get("unread") == "true",
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting notifications")
+		return
+	}
+
+	rows, next := nextCursor(rows, limit, func(notification database.Notification) (time.Time, uuid.UUID) {
+		return notification.CreatedAt, notification.ID
+	})
+	formattedNotifications := make([]Notification, len(rows))
+	for i, notification := range rows {
+		formattedNotifications[i] = formatNotification(notification)
+	}
+
+	respondWithJSON(w, 200, notificationPage{
+		Notifications: formattedNotifications,
+		NextCursor:    next,
+	})
+}
+
+func (cfg *apiConfig) handlerMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		IDs []uuid.UUID `json:"ids"`
+		All bool        `json:"all"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+	if !params.All && len(params.IDs) == 0 {
+		respondWithError(w, 400, "Provide notification ids or set all to true")
+		return
+	}
+
+	if params.All {
+		err = cfg.dbQueries.MarkAllNotificationsRead(r.Context(), userID)
+	} else {
+		err = cfg.dbQueries.MarkNotificationsRead(r.Context(), database.MarkNotificationsReadParams{
+			UserID: userID,
+			Ids:    params.IDs,
+		})
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error updating notifications")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	count, err := cfg.dbQueries.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Error counting notifications")
+		return
+	}
+
+	type unreadCount struct {
+		UnreadCount int64 `json:"unread_count"`
+	}
+	respondWithJSON(w, 200, unreadCount{UnreadCount: count})
+}
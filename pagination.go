@@ -0,0 +1,109 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// pageCursor marks the last row of a page. Rows are ordered by
+// (created_at, id) so that chirps sharing a timestamp keep a stable order.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(formatCursorKey(createdAt, id)))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	return parseCursorKey(string(raw))
+}
+
+func formatCursorKey(createdAt time.Time, id uuid.UUID) string {
+	return strconv.FormatInt(createdAt.UnixMicro(), 10) + "_" + id.String()
+}
+
+func parseCursorKey(raw string) (pageCursor, error) {
+	micros, idStr, found := strings.Cut(raw, "_")
+	if !found {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	unixMicro, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	return pageCursor{CreatedAt: time.UnixMicro(unixMicro).UTC(), ID: id}, nil
+}
+
+// parsePageParams reads the `limit` and `cursor` query parameters. The
+// returned cursor is nil when the first page is requested.
+func parsePageParams(r *http.Request) (int32, *pageCursor, error) {
+	limit, err := parsePageLimit(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cursorParam := r.URL.Query().Get("cursor")
+	if cursorParam == "" {
+		return limit, nil, nil
+	}
+	cursor, err := decodeCursor(cursorParam)
+	if err != nil {
+		return 0, nil, err
+	}
+	return limit, &cursor, nil
+}
+
+func parsePageLimit(r *http.Request) (int32, error) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return int32(min(limit, maxPageLimit)), nil
+}
+
+// cursorParams converts an optional cursor into the nullable arguments shared
+// by the keyset pagination queries.
+func cursorParams(cursor *pageCursor) (sql.NullTime, uuid.NullUUID) {
+	if cursor == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: cursor.ID, Valid: true}
+}
+
+// nextCursor trims the extra row fetched past the limit and returns the
+// cursor for the following page, or nil when there is none.
+func nextCursor[T any](rows []T, limit int32, key func(T) (time.Time, uuid.UUID)) ([]T, *string) {
+	if len(rows) <= int(limit) {
+		return rows, nil
+	}
+	rows = rows[:limit]
+	createdAt, id := key(rows[len(rows)-1])
+	cursor := encodeCursor(createdAt, id)
+	return rows, &cursor
+}
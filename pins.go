@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+// handlerPinChirp pins one of the user's own chirps to their profile,
+// replacing any chirp pinned before.
+func (cfg *apiConfig) handlerPinChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to pin this chirp.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	if !chirp.UserID.Valid || chirp.UserID.UUID != userID {
+		respondWithError(w, 403, "You can only pin your own chirps")
+		return
+	}
+
+	// The query checks ownership again in case the chirp changed since
+	pinned, err := cfg.dbQueries.PinChirp(r.Context(), database.PinChirpParams{
+		ChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+		UserID:  userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error pinning chirp")
+		return
+	}
+	if pinned == 0 {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnpinChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to unpin this chirp.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	unpinned, err := cfg.dbQueries.UnpinChirp(r.Context(), database.UnpinChirpParams{
+		UserID:  userID,
+		ChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error unpinning chirp")
+		return
+	}
+	if unpinned == 0 {
+		respondWithError(w, 404, "Chirp is not pinned")
+		return
+	}
+
+	w.WriteHeader(204)
+}
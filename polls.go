@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rivo/uniseg"
+	"github.com/tiemouie01/chirpy/internal/auth"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+var errPollClosesBeforePublish = errors.New("The poll must close after the chirp is published")
+
+type Poll struct {
+	ClosesAt    string `json:"closes_at"`
+	Closed      bool   `json:"closed"`
+	HideResults bool   `json:"hide_results"`
+	// Set when the viewer cannot see the tallies yet
+	ResultsHidden bool         `json:"results_hidden"`
+	TotalVotes    *int64       `json:"total_votes"`
+	MyVote        *string      `json:"my_vote"`
+	Options       []PollOption `json:"options"`
+}
+
+type PollOption struct {
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+	Votes *int64 `json:"votes"`
+}
+
+type pollParameters struct {
+	Options     []string  `json:"options"`
+	ClosesAt    time.Time `json:"closes_at"`
+	HideResults bool      `json:"hide_results"`
+}
+
+// newPoll is a validated poll that is stored along with its chirp.
+type newPoll struct {
+	options     []string
+	closesAt    time.Time
+	hideResults bool
+}
+
+// preparePoll validates the poll attached to a new chirp and runs its
+// options through the profanity filter. opensAt is when the chirp becomes
+// visible, so a scheduled chirp cannot carry a poll that is already closed.
+func (cfg *apiConfig) preparePoll(params pollParameters, opensAt time.Time) (*newPoll, []string, error) {
+	if len(params.Options) < minPollOptions || len(params.Options) > maxPollOptions {
+		return nil, nil, fmt.Errorf("A poll must have between %d and %d options", minPollOptions, maxPollOptions)
+	}
+	if !params.ClosesAt.After(opensAt) {
+		return nil, nil, errPollClosesBeforePublish
+	}
+	if params.ClosesAt.Sub(opensAt) > maxPollDuration {
+		return nil, nil, fmt.Errorf("A poll can stay open for at most %d days", int(maxPollDuration.Hours()/24))
+	}
+
+	poll := &newPoll{
+		closesAt:    params.ClosesAt.UTC(),
+		hideResults: params.HideResults,
+	}
+	flagged := []string{}
+	seen := make(map[string]bool, len(params.Options))
+	for _, option := range params.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, nil, errors.New("Poll options cannot be empty")
+		}
+		if uniseg.GraphemeClusterCount(option) > maxPollOptionLength {
+			return nil, nil, fmt.Errorf("Poll options can be at most %d characters", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return nil, nil, errors.New("Poll options must be unique")
+		}
+		seen[strings.ToLower(option)] = true
+
+		cleaned, words, err := cfg.cleanChirp(option)
+		if err != nil {
+			return nil, nil, err
+		}
+		poll.options = append(poll.options, cleaned)
+		flagged = append(flagged, words...)
+	}
+	return poll, flagged, nil
+}
+
+func createPoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, poll *newPoll) error {
+	if poll == nil {
+		return nil
+	}
+	err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:     chirpID,
+		ClosesAt:    poll.closesAt,
+		HideResults: poll.hideResults,
+	})
+	if err != nil {
+		return err
+	}
+	return q.CreatePollOptions(ctx, database.CreatePollOptionsParams{
+		ChirpID: chirpID,
+		Texts:   poll.options,
+	})
+}
+
+// getPolls loads the polls of a batch of chirps as the viewer sees them.
+// When the author hides results, tallies are only shown to the author and to
+// users who have voted until the poll closes.
+func (cfg *apiConfig) getPolls(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp) (map[uuid.UUID]*Poll, error) {
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	authors := make(map[uuid.UUID]uuid.NullUUID, len(chirps))
+	for i, chirp := range chirps {
+		chirpIDs[i] = chirp.ID
+		authors[chirp.ID] = chirp.UserID
+	}
+
+	polls, err := cfg.dbQueries.GetPollsForChirps(ctx, chirpIDs)
+	if err != nil || len(polls) == 0 {
+		return nil, err
+	}
+	results, err := cfg.dbQueries.GetPollResults(ctx, database.GetPollResultsParams{
+		ViewerID: viewerID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := make(map[uuid.UUID]*Poll, len(polls))
+	for _, poll := range polls {
+		formatted[poll.ChirpID] = &Poll{
+			ClosesAt:    poll.ClosesAt.String(),
+			Closed:      !poll.ClosesAt.After(time.Now()),
+			HideResults: poll.HideResults,
+			Options:     []PollOption{},
+		}
+	}
+	totals := make(map[uuid.UUID]int64, len(polls))
+	for _, result := range results {
+		poll := formatted[result.ChirpID]
+		poll.Options = append(poll.Options, PollOption{
+			ID:    result.ID.String(),
+			Text:  result.Text,
+			Votes: &result.VoteCount,
+		})
+		totals[result.ChirpID] += result.VoteCount
+		if result.VotedByMe {
+			myVote := result.ID.String()
+			poll.MyVote = &myVote
+		}
+	}
+
+	for chirpID, poll := range formatted {
+		total := totals[chirpID]
+		poll.TotalVotes = &total
+
+		author := authors[chirpID]
+		isAuthor := viewerID.Valid && author.Valid && author.UUID == viewerID.UUID
+		if poll.HideResults && !poll.Closed && poll.MyVote == nil && !isAuthor {
+			poll.ResultsHidden = true
+			poll.TotalVotes = nil
+			for i := range poll.Options {
+				poll.Options[i].Votes = nil
+			}
+		}
+	}
+	return formatted, nil
+}
+
+func (cfg *apiConfig) handlerVoteInPoll(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		OptionID uuid.UUID `json:"option_id"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to vote in this poll.")
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to vote in this poll.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	poll, err := cfg.dbQueries.GetPoll(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Poll not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error casting vote")
+		return
+	}
+	if !poll.ClosesAt.After(time.Now()) {
+		respondWithError(w, 409, "Poll is closed")
+		return
+	}
+
+	voted, err := cfg.dbQueries.CastPollVote(r.Context(), database.CastPollVoteParams{
+		UserID:   userID,
+		OptionID: params.OptionID,
+		ChirpID:  chirpID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error casting vote")
+		return
+	}
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error casting vote")
+		return
+	}
+	if voted == 0 {
+		// Either the user already voted or the option is not part of this poll
+		if formattedChirps[0].Poll != nil && formattedChirps[0].Poll.MyVote != nil {
+			respondWithError(w, 409, "You have already voted in this poll")
+			return
+		}
+		respondWithError(w, 400, "Invalid poll option")
+		return
+	}
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
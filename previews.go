@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	linkPreviewTTL       = 24 * time.Hour
+	linkPreviewQueueSize = 256
+	linkPreviewWorkers   = 2
+	maxPreviewURLLength  = 2048
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	SiteName    string `json:"site_name"`
+}
+
+// firstURL returns the first http(s) link in a chirp body, without any
+// punctuation that ends the sentence around it.
+func firstURL(body string) string {
+	match := urlPattern.FindString(body)
+	match = strings.TrimRight(match, ".,;:!?)]}")
+	if match == "" || len(match) > maxPreviewURLLength {
+		return ""
+	}
+	parsed, err := url.Parse(match)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return match
+}
+
+// queueLinkPreview asks the preview workers to unfurl the first link in a
+// chirp. When the queue is full the link is skipped rather than holding up
+// the request; it is picked up again the next time it is posted.
+func (cfg *apiConfig) queueLinkPreview(body string) {
+	link := firstURL(body)
+	if link == "" {
+		return
+	}
+	select {
+	case cfg.previewQueue <- link:
+	default:
+	}
+}
+
+func (cfg *apiConfig) runLinkPreviewWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case link := <-cfg.previewQueue:
+			err := cfg.refreshLinkPreview(ctx, link)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error saving link preview for %s: %v\n", link, err)
+			}
+		}
+	}
+}
+
+// refreshLinkPreview fetches a link unless it was fetched recently. Failed
+// fetches are cached too, so a broken link is not retried on every chirp.
+func (cfg *apiConfig) refreshLinkPreview(ctx context.Context, link string) error {
+	fresh, err := cfg.dbQueries.IsLinkPreviewFresh(ctx, database.IsLinkPreviewFreshParams{
+		Url:           link,
+		MaxAgeSeconds: int32(linkPreviewTTL.Seconds()),
+	})
+	if err != nil || fresh {
+		return err
+	}
+
+	preview, err := cfg.linkPreviews.Fetch(ctx, link)
+	return cfg.dbQueries.UpsertLinkPreview(ctx, database.UpsertLinkPreviewParams{
+		Url:         link,
+		Failed:      err != nil,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageUrl:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+	})
+}
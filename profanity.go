@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/profanity"
+)
+
+const (
+	profanityBlock = "block"
+	profanityAllow = "allow"
+)
+
+var errProfanity = errors.New("Chirp contains words that are not allowed")
+
+type profanityLists struct {
+	Action  profanity.Action `json:"action"`
+	Builtin []string         `json:"builtin"`
+	Blocked []string         `json:"blocked"`
+	Allowed []string         `json:"allowed"`
+}
+
+type FlaggedChirp struct {
+	Chirp     Chirp    `json:"chirp"`
+	Words     []string `json:"words"`
+	FlaggedAt string   `json:"flagged_at"`
+}
+
+type flaggedChirpPage struct {
+	Flags      []FlaggedChirp `json:"flags"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+// cleanChirp applies the configured profanity action to a chirp body. It
+// returns the body to store and, when flagging, the blocked words it
+// contains.
+func (cfg *apiConfig) cleanChirp(body string) (string, []string, error) {
+	matches := cfg.profanityFilter.Find(body)
+	if len(matches) == 0 {
+		return body, nil, nil
+	}
+
+	switch cfg.profanityAction {
+	case profanity.ActionReject:
+		return "", nil, errProfanity
+	case profanity.ActionFlag:
+		words := make([]string, len(matches))
+		for i, match := range matches {
+			words[i] = match.Word
+		}
+		return body, words, nil
+	default:
+		return profanity.Mask(body, matches), nil, nil
+	}
+}
+
+// flagChirp queues a chirp for review when it contains blocked words.
+func flagChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+	return q.FlagChirp(ctx, database.FlagChirpParams{
+		ChirpID: chirpID,
+		Words:   words,
+	})
+}
+
+// reloadProfanityFilter combines the built-in word list with the words
+// managed through the admin endpoints.
+func (cfg *apiConfig) reloadProfanityFilter(ctx context.Context) error {
+	entries, err := cfg.dbQueries.ListProfanityWords(ctx)
+	if err != nil {
+		return err
+	}
+	blocked := append([]string{}, cfg.profanityWords...)
+	allowed := []string{}
+	for _, entry := range entries {
+		if entry.Kind == profanityAllow {
+			allowed = append(allowed, entry.Word)
+		} else {
+			blocked = append(blocked, entry.Word)
+		}
+	}
+	cfg.profanityFilter.Replace(blocked, allowed)
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetProfanityLists(w http.ResponseWriter, r *http.Request) {
+	_, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+
+	entries, err := cfg.dbQueries.ListProfanityWords(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Error collecting profanity lists")
+		return
+	}
+
+	lists := profanityLists{
+		Action:  cfg.profanityAction,
+		Builtin: cfg.profanityWords,
+		Blocked: []string{},
+		Allowed: []string{},
+	}
+	for _, entry := range entries {
+		if entry.Kind == profanityAllow {
+			lists.Allowed = append(lists.Allowed, entry.Word)
+		} else {
+			lists.Blocked = append(lists.Blocked, entry.Word)
+		}
+	}
+
+	respondWithJSON(w, 200, lists)
+}
+
+func (cfg *apiConfig) handlerAddBlockedWord(w http.ResponseWriter, r *http.Request) {
+	cfg.addProfanityWord(w, r, profanityBlock)
+}
+
+func (cfg *apiConfig) handlerAddAllowedWord(w http.ResponseWriter, r *http.Request) {
+	cfg.addProfanityWord(w, r, profanityAllow)
+}
+
+func (cfg *apiConfig) handlerRemoveBlockedWord(w http.ResponseWriter, r *http.Request) {
+	cfg.removeProfanityWord(w, r, profanityBlock)
+}
+
+func (cfg *apiConfig) handlerRemoveAllowedWord(w http.ResponseWriter, r *http.Request) {
+	cfg.removeProfanityWord(w, r, profanityAllow)
+}
+
+func (cfg *apiConfig) addProfanityWord(w http.ResponseWriter, r *http.Request, kind string) {
+	type parameters struct {
+		Word string `json:"word"`
+	}
+
+	_, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+	word := strings.TrimSpace(params.Word)
+	if word == "" || profanity.Normalize(word) == "" || strings.ContainsAny(word, " \t\n") {
+		respondWithError(w, 400, "Word must be a single non-empty word")
+		return
+	}
+
+	err = cfg.dbQueries.AddProfanityWord(r.Context(), database.AddProfanityWordParams{
+		Word: word,
+		Kind: kind,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error saving word")
+		return
+	}
+	err = cfg.reloadProfanityFilter(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Error reloading profanity filter")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) removeProfanityWord(w http.ResponseWriter, r *http.Request, kind string) {
+	_, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+
+	removed, err := cfg.dbQueries.DeleteProfanityWord(r.Context(), database.DeleteProfanityWordParams{
+		Word: r.PathValue("word"),
+		Kind: kind,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error removing word")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, 404, "Word not found")
+		return
+	}
+	err = cfg.reloadProfanityFilter(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Error reloading profanity filter")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetFlaggedChirps(w http.ResponseWriter, r *http.Request) {
+	adminID, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	rows, err := cfg.dbQueries.ListChirpFlags(r.Context(), database.ListChirpFlagsParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting flagged chirps")
+		return
+	}
+	rows, next := nextCursor(rows, limit, func(row database.ListChirpFlagsRow) (time.Time, uuid.UUID) {
+		return row.FlaggedAt, row.Chirp.ID
+	})
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: adminID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting flagged chirps")
+		return
+	}
+
+	flags := make([]FlaggedChirp, len(rows))
+	for i, row := range rows {
+		flags[i] = FlaggedChirp{
+			Chirp:     formattedChirps[i],
+			Words:     row.Words,
+			FlaggedAt: row.FlaggedAt.String(),
+		}
+	}
+
+	respondWithJSON(w, 200, flaggedChirpPage{Flags: flags, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerDismissChirpFlag(w http.ResponseWriter, r *http.Request) {
+	_, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithAdminError(w, err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	err = cfg.dbQueries.DeleteChirpFlag(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, 500, "Error dismissing flag")
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rivo/uniseg"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+)
+
+var (
+	// At least three characters, so no username can be "me"
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,15}$`)
+
+	errInvalidUsername = errors.New("Usernames must be 3 to 15 letters, digits or underscores")
+)
+
+// Profile is the public view of a user. It never includes their email.
+type Profile struct {
+	ID            uuid.UUID `json:"id"`
+	CreatedAt     string    `json:"created_at"`
+	Username      *string   `json:"username"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	AvatarURL     *string   `json:"avatar_url"`
+	IsChirpyRed   bool      `json:"is_chirpy_red"`
+	PinnedChirpID *string   `json:"pinned_chirp_id"`
+}
+
+// AuthorSummary identifies the author of a chirp.
+type AuthorSummary struct {
+	ID          string  `json:"id"`
+	Username    *string `json:"username"`
+	DisplayName string  `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// optional records whether a field was present in a JSON body, so that a
+// partial update can tell an omitted field from one set to null.
+type optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (o *optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		return nil
+	}
+	o.Value = new(T)
+	return json.Unmarshal(data, o.Value)
+}
+
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errInvalidUsername
+	}
+	return nil
+}
+
+func isUsernameTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_username_idx"
+}
+
+func nullStringPointer(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func (cfg *apiConfig) avatarURL(key sql.NullString) *string {
+	if !key.Valid {
+		return nil
+	}
+	url := cfg.storage.URL(key.String)
+	return &url
+}
+
+func (cfg *apiConfig) formatProfile(profile database.GetProfileByUsernameRow) Profile {
+	return Profile{
+		ID:            profile.ID,
+		CreatedAt:     profile.CreatedAt.String(),
+		Username:      nullStringPointer(profile.Username),
+		DisplayName:   profile.DisplayName,
+		Bio:           profile.Bio,
+		AvatarURL:     cfg.avatarURL(profile.AvatarKey),
+		IsChirpyRed:   profile.IsChirpyRed.Bool,
+		PinnedChirpID: nullUUIDString(profile.PinnedChirpID),
+	}
+}
+
+// getAuthorSummaries loads the authors of a batch of chirps.
+func (cfg *apiConfig) getAuthorSummaries(ctx context.Context, chirps []database.Chirp) (map[uuid.UUID]*AuthorSummary, error) {
+	authorIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.UserID.Valid {
+			authorIDs = append(authorIDs, chirp.UserID.UUID)
+		}
+	}
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := cfg.dbQueries.GetUserSummaries(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	authors := make(map[uuid.UUID]*AuthorSummary, len(rows))
+	for _, row := range rows {
+		authors[row.ID] = &AuthorSummary{
+			ID:          row.ID.String(),
+			Username:    nullStringPointer(row.Username),
+			DisplayName: row.DisplayName,
+			AvatarURL:   cfg.avatarURL(row.AvatarKey),
+		}
+	}
+	return authors, nil
+}
+
+func (cfg *apiConfig) handlerGetProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := cfg.dbQueries.GetProfileByUsername(r.Context(), r.PathValue("username"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error fetching profile")
+		return
+	}
+
+	respondWithJSON(w, 200, cfg.formatProfile(profile))
+}
+
+// handlerUpdateProfile changes only the profile fields present in the
+// request. display_name and bio are cleared with null, and avatar_media_id
+// with null removes the avatar.
+func (cfg *apiConfig) handlerUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Username      optional[string]    `json:"username"`
+		DisplayName   optional[string]    `json:"display_name"`
+		Bio           optional[string]    `json:"bio"`
+		AvatarMediaID optional[uuid.UUID] `json:"avatar_media_id"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	update := database.UpdateUserProfileParams{
+		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarMediaID: user.AvatarMediaID,
+		ID:            userID,
+	}
+
+	if params.Username.Set {
+		if params.Username.Value == nil {
+			respondWithError(w, 400, "Username cannot be removed")
+			return
+		}
+		err = validateUsername(*params.Username.Value)
+		if err != nil {
+			respondWithError(w, 400, err.Error())
+			return
+		}
+		update.Username = sql.NullString{String: *params.Username.Value, Valid: true}
+	}
+	if params.DisplayName.Set {
+		update.DisplayName = ""
+		if params.DisplayName.Value != nil {
+			update.DisplayName = strings.TrimSpace(*params.DisplayName.Value)
+		}
+		if uniseg.GraphemeClusterCount(update.DisplayName) > maxDisplayNameLength {
+			respondWithError(w, 400, "Display name is too long")
+			return
+		}
+	}
+	if params.Bio.Set {
+		update.Bio = ""
+		if params.Bio.Value != nil {
+			update.Bio = strings.TrimSpace(*params.Bio.Value)
+		}
+		if uniseg.GraphemeClusterCount(update.Bio) > maxBioLength {
+			respondWithError(w, 400, "Bio is too long")
+			return
+		}
+	}
+	if params.AvatarMediaID.Set {
+		update.AvatarMediaID = uuid.NullUUID{}
+		if params.AvatarMediaID.Value != nil {
+			// Avatars come from the user's own uploads that are not attached
+			// to a chirp
+			avatar, err := cfg.dbQueries.GetUnattachedMedia(r.Context(), database.GetUnattachedMediaParams{
+				ID:     *params.AvatarMediaID.Value,
+				UserID: userID,
+			})
+			if err != nil {
+				respondWithError(w, 400, errMediaNotFound.Error())
+				return
+			}
+			update.AvatarMediaID = uuid.NullUUID{UUID: avatar.ID, Valid: true}
+		}
+	}
+
+	updated, err := cfg.dbQueries.UpdateUserProfile(r.Context(), update)
+	if isUsernameTaken(err) {
+		respondWithError(w, 409, "Username is already taken")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error updating profile")
+		return
+	}
+
+	profile, err := cfg.dbQueries.GetProfile(r.Context(), updated.ID)
+	if err != nil {
+		respondWithError(w, 500, "Error updating profile")
+		return
+	}
+
+	respondWithJSON(w, 200, cfg.formatProfile(database.GetProfileByUsernameRow(profile)))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+// getRechirpableChirp looks up a chirp that can be rechirped or quoted.
+// Rechirps resolve to the chirp they amplify so references never point at
+// an empty rechirp.
+func getRechirpableChirp(ctx context.Context, q *database.Queries, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := q.GetChirp(ctx, id)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.RechirpOfID.Valid {
+		chirp, err = q.GetChirp(ctx, chirp.RechirpOfID.UUID)
+		if err != nil {
+			return database.Chirp{}, err
+		}
+	}
+	return chirp, nil
+}
+
+func (cfg *apiConfig) handlerRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	original, err := getRechirpableChirp(r.Context(), cfg.dbQueries, chirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	chirp, err := cfg.dbQueries.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		UserID:      uuid.NullUUID{UUID: userID, Valid: true},
+		RechirpOfID: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 409, "You have already rechirped this chirp")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error creating rechirp")
+		return
+	}
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error creating rechirp")
+		return
+	}
+
+	cfg.publishChirpCreated(r.Context(), chirp)
+
+	respondWithJSON(w, 201, formattedChirps[0])
+}
+
+func (cfg *apiConfig) handlerUndoRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to perform this action.")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	rechirpID, err := cfg.dbQueries.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		UserID:      uuid.NullUUID{UUID: userID, Valid: true},
+		RechirpOfID: uuid.NullUUID{UUID: chirpID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Rechirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error removing rechirp")
+		return
+	}
+
+	cfg.publishChirpDeleted(rechirpID, userID)
+
+	w.WriteHeader(204)
+}
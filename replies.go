@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/notifications"
+)
+
+type chirpThread struct {
+	Ancestors []Chirp   `json:"ancestors"`
+	Chirp     Chirp     `json:"chirp"`
+	Replies   chirpPage `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerGetChirpReplies(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	_, err = cfg.dbQueries.GetChirp(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	replies, err := cfg.listReplies(r.Context(), viewerID, id, limit, cursor)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting replies")
+		return
+	}
+
+	respondWithJSON(w, 200, replies)
+}
+
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	// Ancestors come back root first, so the requested chirp goes last
+	ancestors, err := cfg.dbQueries.GetChirpAncestors(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting thread")
+		return
+	}
+	formattedChirps, err := cfg.formatChirps(r.Context(), viewerID, append(ancestors, chirp))
+	if err != nil {
+		respondWithError(w, 500, "Error collecting thread")
+		return
+	}
+
+	replies, err := cfg.listReplies(r.Context(), viewerID, id, limit, cursor)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting replies")
+		return
+	}
+
+	respondWithJSON(w, 200, chirpThread{
+		Ancestors: formattedChirps[:len(ancestors)],
+		Chirp:     formattedChirps[len(ancestors)],
+		Replies:   replies,
+	})
+}
+
+func (cfg *apiConfig) listReplies(ctx context.Context, viewerID uuid.NullUUID, parentID uuid.UUID, limit int32, cursor *pageCursor) (chirpPage, error) {
+	cursorCreatedAt, cursorID := cursorParams(cursor)
+	replies, err := cfg.dbQueries.ListChirpReplies(ctx, database.ListChirpRepliesParams{
+		ParentID:        uuid.NullUUID{UUID: parentID, Valid: true},
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		return chirpPage{}, err
+	}
+	return cfg.newChirpPage(ctx, viewerID, replies, limit)
+}
+
+// notifyReply tells the author of the parent chirp about a new reply.
+func notifyReply(ctx context.Context, q *database.Queries, chirp database.Chirp) (*database.Notification, error) {
+	if !chirp.ParentID.Valid {
+		return nil, nil
+	}
+	// The parent of a scheduled reply may be deleted before it is published
+	parent, err := q.GetChirp(ctx, chirp.ParentID.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !parent.UserID.Valid {
+		return nil, nil
+	}
+	return notifications.Record(ctx, q, notifications.Event{
+		Type:    notifications.TypeReply,
+		UserID:  parent.UserID.UUID,
+		ActorID: chirp.UserID,
+		ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+	})
+}
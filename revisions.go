@@ -0,0 +1,198 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+var (
+	errChirpNotFound    = errors.New("Chirp not found")
+	errNotChirpAuthor   = errors.New("You are not authorized to edit this chirp.")
+	errEditWindowClosed = errors.New("This chirp can no longer be edited")
+	errRechirpNotEdited = errors.New("Rechirps cannot be edited")
+)
+
+type ChirpRevision struct {
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to edit this chirp.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+	limit, err := cfg.chirpLengthLimit(r.Context(), cfg.dbQueries, userID)
+	if err != nil {
+		respondWithError(w, 500, "Error updating chirp")
+		return
+	}
+	err = validateChirpBody(params.Body, limit)
+	if err != nil {
+		respondWithInvalidChirp(w, err, "Error updating chirp")
+		return
+	}
+	cleanedBody, flagged, err := cfg.cleanChirp(params.Body)
+	if err != nil {
+		respondWithInvalidChirp(w, err, "Error updating chirp")
+		return
+	}
+
+	var chirp database.Chirp
+	var created []database.Notification
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		// Lock the chirp so concurrent edits record every revision
+		current, err := q.GetChirpForEdit(r.Context(), database.GetChirpForEditParams{
+			ID:            id,
+			WindowSeconds: int32(cfg.chirpEditWindow.Seconds()),
+		})
+		if errors.Is(err, sql.ErrNoRows) || current.Chirp.DeletedAt.Valid || current.Chirp.ScheduledFor.Valid {
+			return errChirpNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.Chirp.UserID.UUID != userID {
+			return errNotChirpAuthor
+		}
+		if current.Chirp.RechirpOfID.Valid {
+			return errRechirpNotEdited
+		}
+		if !current.Editable {
+			return errEditWindowClosed
+		}
+
+		// The replaced version dates from the chirp's last edit, if any
+		versionCreatedAt := current.Chirp.CreatedAt
+		if current.Chirp.EditedAt.Valid {
+			versionCreatedAt = current.Chirp.EditedAt.Time
+		}
+		err = q.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+			ChirpID:   id,
+			Body:      current.Chirp.Body,
+			CreatedAt: versionCreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		chirp, err = q.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+			ID:   id,
+			Body: cleanedBody,
+		})
+		if err != nil {
+			return err
+		}
+		// A flag on the old body no longer applies once it is edited clean
+		if len(flagged) == 0 {
+			err = q.DeleteChirpFlag(r.Context(), id)
+		} else {
+			err = flagChirp(r.Context(), q, id, flagged)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Re-index the new body, without notifying anyone mentioned before
+		err = q.ClearChirpHashtags(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		err = tagChirp(r.Context(), q, chirp)
+		if err != nil {
+			return err
+		}
+		mentioned, err := q.ClearChirpMentions(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		created, err = mentionUsers(r.Context(), q, chirp, mentioned)
+		return err
+	})
+	switch {
+	case errors.Is(err, errChirpNotFound):
+		respondWithError(w, 404, err.Error())
+		return
+	case errors.Is(err, errNotChirpAuthor), errors.Is(err, errEditWindowClosed):
+		respondWithError(w, 403, err.Error())
+		return
+	case errors.Is(err, errRechirpNotEdited):
+		respondWithError(w, 400, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, 500, "Error updating chirp")
+		return
+	}
+	for _, notification := range created {
+		cfg.publishNotification(&notification)
+	}
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error updating chirp")
+		return
+	}
+
+	cfg.publishChirpUpdated(r.Context(), chirp)
+	cfg.queueLinkPreview(chirp.Body)
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
+
+func (cfg *apiConfig) handlerGetChirpHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	// Deleted chirps keep no visible history
+	_, err = cfg.dbQueries.GetChirp(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	revisions, err := cfg.dbQueries.ListChirpRevisions(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting chirp history")
+		return
+	}
+
+	formattedRevisions := make([]ChirpRevision, len(revisions))
+	for i, revision := range revisions {
+		formattedRevisions[i] = ChirpRevision{
+			Body:       revision.Body,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+		}
+	}
+
+	respondWithJSON(w, 200, formattedRevisions)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	schedulerBatchSize = 100
+	// Upper bound on how long the scheduler sleeps, so chirps scheduled by
+	// other instances are still published on time.
+	schedulerMaxWait = time.Minute
+)
+
+// runScheduler publishes scheduled chirps as they fall due. Pending chirps
+// live in the database, so anything scheduled before a restart is picked up
+// on the first pass.
+func (cfg *apiConfig) runScheduler(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-cfg.schedulerWake:
+		}
+
+		err := cfg.publishDueChirps(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error publishing scheduled chirps: %v\n", err)
+		}
+		timer.Reset(cfg.nextSchedulerWait(ctx))
+	}
+}
+
+// wakeScheduler makes the scheduler look again for the next due chirp after
+// one has been scheduled or rescheduled.
+func (cfg *apiConfig) wakeScheduler() {
+	select {
+	case cfg.schedulerWake <- struct{}{}:
+	default:
+	}
+}
+
+func (cfg *apiConfig) nextSchedulerWait(ctx context.Context) time.Duration {
+	next, err := cfg.dbQueries.GetNextScheduledTime(ctx)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+			log.Printf("Error finding next scheduled chirp: %v\n", err)
+		}
+		return schedulerMaxWait
+	}
+	return max(min(time.Until(next.Time), schedulerMaxWait), 0)
+}
+
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) error {
+	for {
+		ids, err := cfg.dbQueries.ListDueChirpIDs(ctx, schedulerBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			err = cfg.publishScheduledChirp(ctx, id)
+			if err != nil {
+				return err
+			}
+		}
+		if len(ids) < schedulerBatchSize {
+			return nil
+		}
+	}
+}
+
+// publishScheduledChirp makes a due chirp visible as if it had just been
+// posted, then indexes it and sends out its notifications and events.
+func (cfg *apiConfig) publishScheduledChirp(ctx context.Context, id uuid.UUID) error {
+	var chirp database.Chirp
+	var created []database.Notification
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		chirp, err = q.PublishScheduledChirp(ctx, id)
+		if err != nil {
+			return err
+		}
+		created, err = indexChirp(ctx, q, chirp)
+		return err
+	})
+	// Cancelled, rescheduled or already published elsewhere
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, notification := range created {
+		cfg.publishNotification(&notification)
+	}
+
+	cfg.publishChirpCreated(ctx, chirp)
+	cfg.queueLinkPreview(chirp.Body)
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	// Pending chirps are paged by the time they will be published
+	cursorScheduledFor, cursorID := cursorParams(cursor)
+	chirps, err := cfg.dbQueries.ListScheduledChirps(r.Context(), database.ListScheduledChirpsParams{
+		UserID:             uuid.NullUUID{UUID: userID, Valid: true},
+		CursorScheduledFor: cursorScheduledFor,
+		CursorID:           cursorID,
+		PageLimit:          limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error collecting scheduled chirps")
+		return
+	}
+	chirps, next := nextCursor(chirps, limit, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.ScheduledFor.Time, chirp.ID
+	})
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Error collecting scheduled chirps")
+		return
+	}
+
+	respondWithJSON(w, 200, chirpPage{Chirps: formattedChirps, NextCursor: next})
+}
+
+func (cfg *apiConfig) handlerRescheduleChirp(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		PublishAt time.Time `json:"publish_at"`
+	}
+
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to reschedule this chirp.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON")
+		return
+	}
+	if !params.PublishAt.After(time.Now()) {
+		respondWithError(w, 400, "publish_at must be in the future")
+		return
+	}
+
+	var chirp database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		chirp, err = q.RescheduleChirp(r.Context(), database.RescheduleChirpParams{
+			ScheduledFor: sql.NullTime{Time: params.PublishAt.UTC(), Valid: true},
+			ID:           id,
+			UserID:       uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errChirpNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// A poll cannot be closed by the time its chirp appears
+		poll, err := q.GetPoll(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !poll.ClosesAt.After(params.PublishAt) {
+			return errPollClosesBeforePublish
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errChirpNotFound):
+		respondWithError(w, 404, "Scheduled chirp not found")
+		return
+	case errors.Is(err, errPollClosesBeforePublish):
+		respondWithError(w, 400, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, 500, "Error rescheduling chirp")
+		return
+	}
+	cfg.wakeScheduler()
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error rescheduling chirp")
+		return
+	}
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to cancel this chirp.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	// Attachments are removed with the chirp they were uploaded for
+	var removed []database.Media
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		removed, err = q.DeleteScheduledChirpMedia(r.Context(), database.DeleteScheduledChirpMediaParams{
+			ID:     id,
+			UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		cancelled, err := q.CancelScheduledChirp(r.Context(), database.CancelScheduledChirpParams{
+			ID:     id,
+			UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if cancelled == 0 {
+			return errChirpNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, errChirpNotFound) {
+		respondWithError(w, 404, "Scheduled chirp not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error cancelling chirp")
+		return
+	}
+	cfg.deleteMediaFiles(r.Context(), removed)
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+// Search results are ordered by rank, so their cursors carry the rank of
+// the last row in addition to the usual (created_at, id) key.
+type searchCursor struct {
+	Rank float32
+	pageCursor
+}
+
+func encodeSearchCursor(row database.SearchChirpsRow) string {
+	raw := strconv.FormatFloat(float64(row.Rank), 'g', -1, 32) + "_" + formatCursorKey(row.Chirp.CreatedAt, row.Chirp.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSearchCursor(s string) (searchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return searchCursor{}, errors.New("invalid cursor")
+	}
+	rankStr, key, found := strings.Cut(string(raw), "_")
+	if !found {
+		return searchCursor{}, errors.New("invalid cursor")
+	}
+	rank, err := strconv.ParseFloat(rankStr, 32)
+	if err != nil {
+		return searchCursor{}, errors.New("invalid cursor")
+	}
+	cursor, err := parseCursorKey(key)
+	if err != nil {
+		return searchCursor{}, err
+	}
+	return searchCursor{Rank: float32(rank), pageCursor: cursor}, nil
+}
+
+// buildSearchQuery turns user input into a to_tsquery expression. Every term
+// must match, text in double quotes is matched as a phrase and a trailing *
+// turns a term into a prefix match.
+func buildSearchQuery(q string) (string, error) {
+	terms := []string{}
+	for i, part := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			words := searchWords(part)
+			if len(words) > 0 {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			words := searchWords(field)
+			if len(words) == 0 {
+				continue
+			}
+			if strings.HasSuffix(field, "*") {
+				words[len(words)-1] += ":*"
+			}
+			terms = append(terms, words...)
+		}
+	}
+	if len(terms) == 0 {
+		return "", errors.New("search query must contain at least one word")
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+// searchWords keeps only letters and digits so user input can never inject
+// tsquery operators.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query, err := buildSearchQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	limit, err := parsePageLimit(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	viewerID, err := cfg.viewerFromRequest(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	searchParams := database.SearchChirpsParams{
+		Query:     query,
+		ViewerID:  viewerID,
+		PageLimit: limit + 1,
+	}
+	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+		authorUUID, err := uuid.Parse(authorID)
+		if err != nil {
+			respondWithError(w, 400, "Invalid authorID format")
+			return
+		}
+		searchParams.AuthorID = uuid.NullUUID{UUID: authorUUID, Valid: true}
+	}
+	if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+		cursor, err := decodeSearchCursor(cursorParam)
+		if err != nil {
+			respondWithError(w, 400, err.Error())
+			return
+		}
+		searchParams.CursorRank = sql.NullFloat64{Float64: float64(cursor.Rank), Valid: true}
+		searchParams.CursorCreatedAt, searchParams.CursorID = cursorParams(&cursor.pageCursor)
+	}
+
+	rows, err := cfg.dbQueries.SearchChirps(r.Context(), searchParams)
+	if err != nil {
+		respondWithError(w, 500, "Error searching chirps")
+		return
+	}
+
+	var next *string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		cursor := encodeSearchCursor(rows[len(rows)-1])
+		next = &cursor
+	}
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	formattedChirps, err := cfg.formatChirps(r.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Error searching chirps")
+		return
+	}
+
+	respondWithJSON(w, 200, chirpPage{
+		Chirps:     formattedChirps,
+		NextCursor: next,
+	})
+}
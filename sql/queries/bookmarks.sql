@@ -0,0 +1,79 @@
+-- name: CreateBookmarkCollection :one
+INSERT INTO bookmark_collections (id, created_at, updated_at, user_id, name)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: CountBookmarkCollections :one
+SELECT COUNT(*) FROM bookmark_collections
+WHERE user_id = $1;
+
+-- name: GetBookmarkCollection :one
+SELECT * FROM bookmark_collections
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListBookmarkCollections :many
+SELECT
+    bookmark_collections.*,
+    COUNT(chirps.id) AS bookmark_count
+FROM bookmark_collections
+LEFT JOIN bookmarks ON bookmarks.collection_id = bookmark_collections.id
+LEFT JOIN chirps ON chirps.id = bookmarks.chirp_id AND chirps.deleted_at IS NULL
+WHERE bookmark_collections.user_id = $1
+GROUP BY bookmark_collections.id
+ORDER BY LOWER(bookmark_collections.name);
+
+-- name: RenameBookmarkCollection :one
+UPDATE bookmark_collections
+SET name = $1, updated_at = NOW()
+WHERE bookmark_collections.id = $2 AND bookmark_collections.user_id = $3
+RETURNING
+    *,
+    (
+        SELECT COUNT(*)
+        FROM bookmarks
+        JOIN chirps ON chirps.id = bookmarks.chirp_id
+        WHERE bookmarks.collection_id = bookmark_collections.id AND chirps.deleted_at IS NULL
+    ) AS bookmark_count;
+
+-- name: DeleteBookmarkCollection :execrows
+DELETE FROM bookmark_collections
+WHERE id = $1 AND user_id = $2;
+
+-- name: AddBookmark :exec
+INSERT INTO bookmarks (collection_id, chirp_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveBookmark :execrows
+DELETE FROM bookmarks
+WHERE collection_id = $1 AND chirp_id = $2 AND user_id = $3;
+
+-- name: ListBookmarkedChirps :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.collection_id = sqlc.arg('collection_id')
+  AND chirps.deleted_at IS NULL
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (bookmarks.created_at, bookmarks.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetBookmarkedChirpIDs :many
+SELECT DISTINCT chirp_id FROM bookmarks
+WHERE user_id = sqlc.arg('user_id')
+  AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
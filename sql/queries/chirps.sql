@@ -1,36 +1,216 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, quoted_chirp_id, scheduled_for)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
     $2
 )
+ON CONFLICT (user_id, rechirp_of_id) WHERE rechirp_of_id IS NOT NULL DO NOTHING
 RETURNING *;
 
--- name: GetAllChirps :many
+-- name: DeleteRechirp :one
+DELETE FROM chirps
+WHERE user_id = $1 AND rechirp_of_id = $2
+RETURNING id;
+
+-- name: GetChirp :one
 SELECT * FROM chirps
-ORDER BY created_at;
+WHERE id = $1 AND deleted_at IS NULL AND scheduled_for IS NULL;
 
--- name: GetAllChirpsDesc :many
+-- name: GetChirpsByIDs :many
 SELECT * FROM chirps
-ORDER BY created_at DESC;
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
 
--- name: GetChirp :one
+-- name: SoftDeleteChirp :execrows
+UPDATE chirps
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND scheduled_for IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
+  AND tombstoned_at IS NULL
+  AND moderated_at IS NULL
+  AND deleted_at > NOW() - (sqlc.arg('grace_seconds')::int * INTERVAL '1 second')
+RETURNING *;
+
+-- name: ListChirps :many
 SELECT * FROM chirps
-WHERE id = $1;
+WHERE deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = sqlc.narg('viewer_id')::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (sqlc.narg('exclude_id')::uuid IS NULL OR id <> sqlc.narg('exclude_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at, id
+LIMIT sqlc.arg('page_limit');
 
--- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1 AND user_id = $2;
+-- name: ListChirpsDesc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = sqlc.narg('viewer_id')::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (sqlc.narg('exclude_id')::uuid IS NULL OR id <> sqlc.narg('exclude_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
 
--- name: GetAllChirpsByAuthor :many
+-- name: ListTimelineChirps :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at;
+WHERE user_id IN (
+    SELECT followee_id FROM follows
+    WHERE follower_id = sqlc.arg('follower_id')
+)
+  AND deleted_at IS NULL
+  AND scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = sqlc.narg('viewer_id')::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListChirpReplies :many
+SELECT * FROM chirps
+WHERE chirps.parent_id = sqlc.arg('parent_id')
+  AND chirps.scheduled_for IS NULL
+  AND (
+    chirps.deleted_at IS NULL
+    OR EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id)
+  )
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY chirps.created_at, chirps.id
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.parent_id AS id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = $1
+    UNION ALL
+    SELECT chirps.parent_id, ancestors.depth + 1
+    FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.id
+)
+SELECT chirps.* FROM chirps
+JOIN ancestors ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC;
+
+-- name: TombstoneExpiredChirps :execrows
+UPDATE chirps
+SET body = '', tombstoned_at = NOW()
+WHERE chirps.deleted_at < NOW() - (sqlc.arg('retention_seconds')::int * INTERVAL '1 second')
+  AND chirps.tombstoned_at IS NULL
+  AND EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id);
+
+-- name: PurgeExpiredChirps :execrows
+DELETE FROM chirps
+WHERE chirps.deleted_at < NOW() - (sqlc.arg('retention_seconds')::int * INTERVAL '1 second')
+  AND NOT EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id);
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.search_vector, to_tsquery('english', sqlc.arg('query'))) AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', sqlc.arg('query'))
+  AND chirps.deleted_at IS NULL
+  AND chirps.scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = sqlc.narg('viewer_id')::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  )
+  AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+  AND (
+    sqlc.narg('cursor_rank')::real IS NULL
+    OR (ts_rank(chirps.search_vector, to_tsquery('english', sqlc.arg('query'))), chirps.created_at, chirps.id)
+      < (sqlc.narg('cursor_rank')::real, sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
 
--- name: GetAllChirpsByAuthorDesc :many
+-- name: ListScheduledChirps :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at DESC;
+WHERE user_id = sqlc.arg('user_id')
+  AND scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+  AND (
+    sqlc.narg('cursor_scheduled_for')::timestamp IS NULL
+    OR (scheduled_for, id) > (sqlc.narg('cursor_scheduled_for')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY scheduled_for, id
+LIMIT sqlc.arg('page_limit');
+
+-- name: RescheduleChirp :one
+UPDATE chirps
+SET scheduled_for = sqlc.arg('scheduled_for'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
+  AND scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CancelScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND scheduled_for IS NOT NULL;
+
+-- name: ListDueChirpIDs :many
+SELECT id FROM chirps
+WHERE scheduled_for <= (NOW() AT TIME ZONE 'UTC')
+  AND deleted_at IS NULL
+ORDER BY scheduled_for, id
+LIMIT $1;
+
+-- name: GetNextScheduledTime :one
+SELECT scheduled_for FROM chirps
+WHERE scheduled_for IS NOT NULL
+  AND deleted_at IS NULL
+ORDER BY scheduled_for
+LIMIT 1;
+
+-- name: PublishScheduledChirp :one
+UPDATE chirps
+SET scheduled_for = NULL, created_at = NOW(), updated_at = NOW()
+WHERE id = $1
+  AND scheduled_for <= (NOW() AT TIME ZONE 'UTC')
+  AND deleted_at IS NULL
+RETURNING *;
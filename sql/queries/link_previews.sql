@@ -0,0 +1,30 @@
+-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (url, fetched_at, failed, title, description, image_url, site_name)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (url) DO UPDATE
+SET fetched_at = NOW(),
+    failed = EXCLUDED.failed,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    site_name = EXCLUDED.site_name;
+
+-- name: IsLinkPreviewFresh :one
+SELECT EXISTS (
+    SELECT 1 FROM link_previews
+    WHERE url = sqlc.arg('url')
+      AND fetched_at > NOW() - (sqlc.arg('max_age_seconds')::int * INTERVAL '1 second')
+);
+
+-- name: GetLinkPreviews :many
+SELECT * FROM link_previews
+WHERE url = ANY(sqlc.arg('urls')::text[])
+  AND NOT failed;
@@ -0,0 +1,55 @@
+-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: AttachMedia :execrows
+UPDATE media
+SET chirp_id = sqlc.arg('chirp_id'),
+    position = array_position(sqlc.arg('ids')::uuid[], id)
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+  AND user_id = sqlc.arg('user_id')
+  AND chirp_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_media_id = media.id);
+
+-- name: GetMediaForChirps :many
+SELECT * FROM media
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: DeleteScheduledChirpMedia :many
+DELETE FROM media
+WHERE chirp_id IN (
+    SELECT id FROM chirps
+    WHERE chirps.id = $1 AND chirps.user_id = $2 AND chirps.scheduled_for IS NOT NULL
+)
+RETURNING *;
+
+-- name: DeleteExpiredChirpMedia :many
+DELETE FROM media
+WHERE chirp_id IN (
+    SELECT id FROM chirps
+    WHERE chirps.deleted_at < NOW() - (sqlc.arg('retention_seconds')::int * INTERVAL '1 second')
+)
+RETURNING *;
+
+-- name: DeleteAbandonedMedia :many
+DELETE FROM media
+WHERE chirp_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_media_id = media.id)
+  AND created_at < NOW() - (sqlc.arg('max_age_seconds')::int * INTERVAL '1 second')
+RETURNING *;
+
+-- name: GetUnattachedMedia :one
+SELECT * FROM media
+WHERE id = $1 AND user_id = $2 AND chirp_id IS NULL;
@@ -0,0 +1,37 @@
+-- name: FindUsersByUsername :many
+SELECT id, LOWER(username)::text AS handle FROM users
+WHERE LOWER(username) = ANY(sqlc.arg('handles')::text[]);
+
+-- name: CreateMention :exec
+INSERT INTO mentions (chirp_id, user_id, start_offset, end_offset, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+);
+
+-- name: GetMentionsForChirps :many
+SELECT * FROM mentions
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, start_offset;
+
+-- name: ListMentioningChirps :many
+SELECT * FROM chirps
+WHERE id IN (
+    SELECT chirp_id FROM mentions
+    WHERE mentions.user_id = sqlc.arg('user_id')
+)
+  AND deleted_at IS NULL
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ClearChirpMentions :many
+DELETE FROM mentions
+WHERE chirp_id = $1
+RETURNING user_id;
@@ -0,0 +1,68 @@
+-- name: GetChirpForModeration :one
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: HideChirp :one
+UPDATE chirps
+SET deleted_at = NOW(), moderated_at = NOW(), updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NULL
+  AND scheduled_for IS NULL
+RETURNING *;
+
+-- name: UnhideChirp :one
+UPDATE chirps
+SET deleted_at = NULL, moderated_at = NULL, updated_at = NOW()
+WHERE id = $1
+  AND moderated_at IS NOT NULL
+  AND tombstoned_at IS NULL
+RETURNING *;
+
+-- name: TombstoneChirp :execrows
+UPDATE chirps
+SET body = '',
+    tombstoned_at = NOW(),
+    deleted_at = COALESCE(chirps.deleted_at, NOW()),
+    moderated_at = NOW(),
+    updated_at = NOW()
+WHERE chirps.id = $1
+  AND EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id);
+
+-- name: DeleteChirp :execrows
+DELETE FROM chirps
+WHERE chirps.id = $1
+  AND NOT EXISTS (SELECT 1 FROM chirps AS replies WHERE replies.parent_id = chirps.id);
+
+-- name: DeleteChirpMedia :many
+DELETE FROM media
+WHERE chirp_id = $1
+RETURNING *;
+
+-- name: DeleteChirpRevisions :exec
+DELETE FROM chirp_revisions
+WHERE chirp_id = $1;
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, moderator_id, action, chirp_id, chirp_user_id, chirp_body, report_ids, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    sqlc.arg('report_ids')::uuid[],
+    $6
+)
+RETURNING *;
+
+-- name: ListModerationActions :many
+SELECT * FROM moderation_actions
+WHERE (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
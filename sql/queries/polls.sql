@@ -0,0 +1,44 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, closes_at, hide_results)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3
+);
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options (id, chirp_id, position, text)
+SELECT gen_random_uuid(), sqlc.arg('chirp_id'), options.position::int, options.text
+FROM unnest(sqlc.arg('texts')::text[]) WITH ORDINALITY AS options(text, position);
+
+-- name: GetPoll :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: GetPollsForChirps :many
+SELECT * FROM polls
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetPollResults :many
+SELECT
+    poll_options.id,
+    poll_options.chirp_id,
+    poll_options.text,
+    COUNT(poll_votes.user_id) AS vote_count,
+    COALESCE(BOOL_OR(poll_votes.user_id = sqlc.narg('viewer_id')::uuid), false)::boolean AS voted_by_me
+FROM poll_options
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE poll_options.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY poll_options.id
+ORDER BY poll_options.chirp_id, poll_options.position;
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes (chirp_id, user_id, option_id, created_at)
+SELECT poll_options.chirp_id, sqlc.arg('user_id'), poll_options.id, NOW()
+FROM poll_options
+JOIN polls ON polls.chirp_id = poll_options.chirp_id
+WHERE poll_options.id = sqlc.arg('option_id')
+  AND poll_options.chirp_id = sqlc.arg('chirp_id')
+  AND polls.closes_at > (NOW() AT TIME ZONE 'UTC')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,42 @@
+-- name: ListProfanityWords :many
+SELECT * FROM profanity_words
+ORDER BY kind, word;
+
+-- name: AddProfanityWord :exec
+INSERT INTO profanity_words (word, kind, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteProfanityWord :execrows
+DELETE FROM profanity_words
+WHERE word = $1 AND kind = $2;
+
+-- name: FlagChirp :exec
+INSERT INTO chirp_flags (chirp_id, words, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (chirp_id) DO UPDATE
+SET words = EXCLUDED.words, created_at = NOW();
+
+-- name: ListChirpFlags :many
+SELECT sqlc.embed(chirps), chirp_flags.words, chirp_flags.created_at AS flagged_at
+FROM chirp_flags
+JOIN chirps ON chirps.id = chirp_flags.chirp_id
+WHERE chirps.deleted_at IS NULL
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirp_flags.created_at, chirp_flags.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY chirp_flags.created_at DESC, chirp_flags.chirp_id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeleteChirpFlag :exec
+DELETE FROM chirp_flags
+WHERE chirp_id = $1;
@@ -0,0 +1,33 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, chirp_id, reporter_id, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (chirp_id, reporter_id) WHERE status = 'open' DO NOTHING
+RETURNING *;
+
+-- name: ListReports :many
+SELECT * FROM reports
+WHERE status = sqlc.arg('status')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at, id
+LIMIT sqlc.arg('page_limit');
+
+-- name: DismissReport :one
+UPDATE reports
+SET status = 'dismissed', resolved_at = NOW(), resolved_by = sqlc.arg('moderator_id')
+WHERE id = sqlc.arg('id') AND status = 'open'
+RETURNING *;
+
+-- name: ResolveChirpReports :many
+UPDATE reports
+SET status = 'resolved', resolved_at = NOW(), resolved_by = sqlc.arg('moderator_id')
+WHERE chirp_id = sqlc.arg('chirp_id') AND status = 'open'
+RETURNING id;
@@ -0,0 +1,32 @@
+-- name: GetChirpForEdit :one
+SELECT
+    sqlc.embed(chirps),
+    (chirps.created_at > NOW() - (sqlc.arg('window_seconds')::int * INTERVAL '1 second'))::boolean AS editable
+FROM chirps
+WHERE chirps.id = sqlc.arg('id')
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, edited_at = NOW(), updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: CreateChirpRevision :exec
+INSERT INTO chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+);
+
+-- name: ListChirpRevisions :many
+SELECT * FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY replaced_at DESC;
+
+-- name: DeleteTombstonedChirpRevisions :execrows
+DELETE FROM chirp_revisions
+WHERE chirp_id IN (SELECT id FROM chirps WHERE tombstoned_at IS NOT NULL);
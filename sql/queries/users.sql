@@ -1,11 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
@@ -29,4 +30,81 @@ WHERE id = $1;
 -- name: UpgradeUser :exec
 UPDATE users
 SET is_chirpy_red = true
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: PinChirp :execrows
+UPDATE users
+SET pinned_chirp_id = sqlc.arg('chirp_id'), updated_at = NOW()
+WHERE users.id = sqlc.arg('user_id')
+  AND EXISTS (
+    SELECT 1 FROM chirps
+    WHERE chirps.id = sqlc.arg('chirp_id')
+      AND chirps.user_id = users.id
+      AND chirps.deleted_at IS NULL
+      AND chirps.scheduled_for IS NULL
+  );
+
+-- name: UnpinChirp :execrows
+UPDATE users
+SET pinned_chirp_id = NULL, updated_at = NOW()
+WHERE id = sqlc.arg('user_id') AND pinned_chirp_id = sqlc.arg('chirp_id');
+
+-- name: GetPinnedChirp :one
+SELECT chirps.* FROM users
+JOIN chirps ON chirps.id = users.pinned_chirp_id
+WHERE users.id = sqlc.arg('user_id')
+  AND chirps.deleted_at IS NULL
+  AND chirps.scheduled_for IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_authors
+    WHERE hidden_authors.viewer_id = sqlc.narg('viewer_id')::uuid
+      AND hidden_authors.author_id = chirps.user_id
+  );
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET username = $1, display_name = $2, bio = $3, avatar_media_id = $4, updated_at = NOW()
+WHERE id = $5
+RETURNING *;
+
+-- name: GetProfileByUsername :one
+SELECT
+    users.id,
+    users.created_at,
+    users.username,
+    users.display_name,
+    users.bio,
+    users.is_chirpy_red,
+    users.pinned_chirp_id,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE LOWER(users.username) = LOWER(sqlc.arg('username'));
+
+-- name: GetProfile :one
+SELECT
+    users.id,
+    users.created_at,
+    users.username,
+    users.display_name,
+    users.bio,
+    users.is_chirpy_red,
+    users.pinned_chirp_id,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE users.id = $1;
+
+-- name: GetUserSummaries :many
+SELECT
+    users.id,
+    users.username,
+    users.display_name,
+    media.thumbnail_key AS avatar_key
+FROM users
+LEFT JOIN media ON media.id = users.avatar_media_id
+WHERE users.id = ANY(sqlc.arg('ids')::uuid[]);
@@ -0,0 +1,14 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD parent_id uuid REFERENCES chirps(id) ON DELETE SET NULL,
+ADD tombstoned_at TIMESTAMP;
+
+CREATE INDEX chirps_parent_id_created_at_id_idx ON chirps (parent_id, created_at, id);
+
+-- +goose Down
+DROP INDEX chirps_parent_id_created_at_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN tombstoned_at,
+DROP COLUMN parent_id;
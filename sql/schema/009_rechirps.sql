@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD rechirp_of_id uuid REFERENCES chirps(id) ON DELETE CASCADE,
+ADD quoted_chirp_id uuid REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_id_key ON chirps (user_id, rechirp_of_id)
+WHERE rechirp_of_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_user_id_rechirp_of_id_key;
+
+ALTER TABLE chirps
+DROP COLUMN quoted_chirp_id,
+DROP COLUMN rechirp_of_id;
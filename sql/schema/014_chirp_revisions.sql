@@ -0,0 +1,21 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD edited_at TIMESTAMP;
+
+CREATE TABLE "chirp_revisions" (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    chirp_id uuid NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_revisions_chirp_id_idx ON chirp_revisions (chirp_id, replaced_at);
+
+-- +goose Down
+DROP TABLE chirp_revisions;
+
+ALTER TABLE chirps
+DROP COLUMN edited_at;
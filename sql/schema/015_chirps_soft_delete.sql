@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD deleted_at TIMESTAMP;
+
+-- Existing tombstones count as deleted chirps
+UPDATE chirps SET deleted_at = tombstoned_at WHERE tombstoned_at IS NOT NULL;
+
+CREATE INDEX chirps_deleted_at_idx ON chirps (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_deleted_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_at;
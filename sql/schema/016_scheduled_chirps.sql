@@ -0,0 +1,14 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD scheduled_for TIMESTAMP;
+
+CREATE INDEX chirps_scheduled_for_idx ON chirps (scheduled_for) WHERE scheduled_for IS NOT NULL;
+CREATE INDEX chirps_user_id_scheduled_for_idx ON chirps (user_id, scheduled_for, id) WHERE scheduled_for IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_user_id_scheduled_for_idx;
+DROP INDEX chirps_scheduled_for_idx;
+
+ALTER TABLE chirps
+DROP COLUMN scheduled_for;
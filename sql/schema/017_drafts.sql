@@ -0,0 +1,17 @@
+-- +goose Up
+
+CREATE TABLE "drafts" (
+    id uuid PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id uuid NOT NULL,
+    body TEXT NOT NULL,
+    parent_id uuid,
+    quoted_chirp_id uuid,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX drafts_user_id_updated_at_id_idx ON drafts (user_id, updated_at, id);
+
+-- +goose Down
+DROP TABLE drafts;
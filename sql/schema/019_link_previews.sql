@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE "link_previews" (
+    url TEXT PRIMARY KEY,
+    fetched_at TIMESTAMP NOT NULL,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE link_previews;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+ALTER TABLE users
+ADD is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE "profanity_words" (
+    word TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('block', 'allow')),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (word, kind)
+);
+
+CREATE TABLE "chirp_flags" (
+    chirp_id uuid PRIMARY KEY,
+    words TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_flags_created_at_idx ON chirp_flags (created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_flags;
+DROP TABLE profanity_words;
+
+ALTER TABLE users
+DROP COLUMN is_admin;
@@ -0,0 +1,45 @@
+-- +goose Up
+
+ALTER TABLE chirps
+ADD moderated_at TIMESTAMP;
+
+CREATE TABLE "reports" (
+    id uuid PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id uuid,
+    reporter_id uuid NOT NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolved_at TIMESTAMP,
+    resolved_by uuid,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE SET NULL,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- A user can only have one open report per chirp
+CREATE UNIQUE INDEX reports_open_chirp_id_reporter_id_idx ON reports (chirp_id, reporter_id) WHERE status = 'open';
+CREATE INDEX reports_status_created_at_id_idx ON reports (status, created_at, id);
+
+-- The audit trail keeps a copy of the chirp so it outlives the chirp itself
+CREATE TABLE "moderation_actions" (
+    id uuid PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    moderator_id uuid,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'unhide', 'remove', 'dismiss')),
+    chirp_id uuid,
+    chirp_user_id uuid,
+    chirp_body TEXT NOT NULL DEFAULT '',
+    report_ids uuid[] NOT NULL DEFAULT '{}',
+    note TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX moderation_actions_created_at_id_idx ON moderation_actions (created_at, id);
+
+-- +goose Down
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+
+ALTER TABLE chirps
+DROP COLUMN moderated_at;
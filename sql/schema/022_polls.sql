@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE "polls" (
+    chirp_id uuid PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    closes_at TIMESTAMP NOT NULL,
+    hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE TABLE "poll_options" (
+    id uuid PRIMARY KEY,
+    chirp_id uuid NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE (chirp_id, position),
+    -- Lets poll_votes check that an option belongs to the voted poll
+    UNIQUE (chirp_id, id),
+    FOREIGN KEY (chirp_id) REFERENCES polls(chirp_id) ON DELETE CASCADE
+);
+
+-- One vote per user per poll
+CREATE TABLE "poll_votes" (
+    chirp_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    option_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id),
+    FOREIGN KEY (chirp_id, option_id) REFERENCES poll_options(chirp_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX poll_votes_option_id_idx ON poll_votes (option_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE "bookmark_collections" (
+    id uuid PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id uuid NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Collection names are unique per user, ignoring case
+CREATE UNIQUE INDEX bookmark_collections_user_id_name_idx ON bookmark_collections (user_id, LOWER(name));
+
+CREATE TABLE "bookmarks" (
+    collection_id uuid NOT NULL,
+    chirp_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (collection_id, chirp_id),
+    FOREIGN KEY (collection_id) REFERENCES bookmark_collections(id) ON DELETE CASCADE,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX bookmarks_collection_id_created_at_idx ON bookmarks (collection_id, created_at, chirp_id);
+CREATE INDEX bookmarks_user_id_chirp_id_idx ON bookmarks (user_id, chirp_id);
+
+-- +goose Down
+DROP TABLE bookmarks;
+DROP TABLE bookmark_collections;
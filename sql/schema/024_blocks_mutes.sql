@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE "blocks" (
+    blocker_id uuid NOT NULL,
+    blocked_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE TABLE "mutes" (
+    muter_id uuid NOT NULL,
+    muted_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (muter_id, muted_id),
+    FOREIGN KEY (muter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (muter_id <> muted_id)
+);
+
+-- Authors whose chirps are filtered out of a viewer's lists
+CREATE VIEW hidden_authors AS
+SELECT blocker_id AS viewer_id, blocked_id AS author_id FROM blocks
+UNION ALL
+SELECT muter_id AS viewer_id, muted_id AS author_id FROM mutes;
+
+-- +goose Down
+DROP VIEW hidden_authors;
+DROP TABLE mutes;
+DROP TABLE blocks;
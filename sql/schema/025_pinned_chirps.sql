@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD pinned_chirp_id uuid REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN pinned_chirp_id;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users
+ADD username TEXT CHECK (username ~ '^[A-Za-z0-9_]{3,15}$'),
+ADD display_name TEXT NOT NULL DEFAULT '',
+ADD bio TEXT NOT NULL DEFAULT '',
+ADD avatar_media_id uuid REFERENCES media(id) ON DELETE SET NULL;
+
+-- Usernames are unique ignoring case, but keep the case they were chosen in
+CREATE UNIQUE INDEX users_username_idx ON users (LOWER(username));
+
+-- +goose Down
+DROP INDEX users_username_idx;
+
+ALTER TABLE users
+DROP COLUMN avatar_media_id,
+DROP COLUMN bio,
+DROP COLUMN display_name,
+DROP COLUMN username;
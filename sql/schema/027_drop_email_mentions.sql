@@ -0,0 +1,15 @@
+-- +goose Up
+-- Mentions were resolved against the local part of email addresses, so the
+-- stored ones reveal which user has which address
+DELETE FROM mentions;
+
+-- +goose Down
+-- The deleted mentions cannot be restored, so this migration cannot be
+-- rolled back
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'migration 027_drop_email_mentions is irreversible';
+END
+$$;
+-- +goose StatementEnd
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/broker"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const (
+	chirpsTopic       = "chirps"
+	streamBufferSize  = 64
+	heartbeatInterval = 15 * time.Second
+	streamReplayLimit = 100
+	eventChirpCreated = "chirp.created"
+	eventChirpUpdated = "chirp.updated"
+	eventChirpDeleted = "chirp.deleted"
+)
+
+func authorTopic(userID uuid.UUID) string {
+	return "authors/" + userID.String()
+}
+
+type deletedChirp struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// formatEventChirp formats a chirp for realtime subscribers. Every
+// subscriber receives the same data, so it is formatted without a viewer and
+// carries no per-viewer fields such as liked_by_me, bookmarked or the
+// author's view of hidden poll results.
+func (cfg *apiConfig) formatEventChirp(ctx context.Context, chirp database.Chirp) (Chirp, bool) {
+	formattedChirps, err := cfg.formatChirps(ctx, uuid.NullUUID{}, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error formatting chirp %s for subscribers: %v\n", chirp.ID, err)
+		return Chirp{}, false
+	}
+	return formattedChirps[0], true
+}
+
+// publishChirpCreated announces a new chirp. The event ID is the chirp's
+// pagination cursor so streaming clients can resume from it.
+func (cfg *apiConfig) publishChirpCreated(ctx context.Context, chirp database.Chirp) {
+	formattedChirp, ok := cfg.formatEventChirp(ctx, chirp)
+	if !ok {
+		return
+	}
+	id := encodeCursor(chirp.CreatedAt, chirp.ID)
+	for _, topic := range []string{chirpsTopic, authorTopic(chirp.UserID.UUID)} {
+		cfg.events.Publish(broker.Message{
+			Topic: topic,
+			Event: eventChirpCreated,
+			ID:    id,
+			Key:   id,
+			Data:  formattedChirp,
+		})
+	}
+}
+
+// publishChirpUpdated announces an edit. Like deletions, edits are not
+// replayed to clients that reconnect.
+func (cfg *apiConfig) publishChirpUpdated(ctx context.Context, chirp database.Chirp) {
+	formattedChirp, ok := cfg.formatEventChirp(ctx, chirp)
+	if !ok {
+		return
+	}
+	key := uuid.NewString()
+	for _, topic := range []string{chirpsTopic, authorTopic(chirp.UserID.UUID)} {
+		cfg.events.Publish(broker.Message{
+			Topic: topic,
+			Event: eventChirpUpdated,
+			Key:   key,
+			Data:  formattedChirp,
+		})
+	}
+}
+
+// publishChirpDeleted announces a deletion. Deletions carry no event ID, so
+// they are not replayed to clients that reconnect.
+func (cfg *apiConfig) publishChirpDeleted(chirpID, authorID uuid.UUID) {
+	key := uuid.NewString()
+	for _, topic := range []string{chirpsTopic, authorTopic(authorID)} {
+		cfg.events.Publish(broker.Message{
+			Topic: topic,
+			Event: eventChirpDeleted,
+			Key:   key,
+			Data:  deletedChirp{ID: chirpID},
+		})
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, msg broker.Message) error {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return err
+	}
+	if msg.ID != "" {
+		_, err = fmt.Fprintf(w, "id: %s\n", msg.ID)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Event, data)
+	return err
+}
+
+// cursorAfter reports whether a sorts after b in (created_at, id) order,
+// matching how Postgres compares the same row values.
+func cursorAfter(a, b pageCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
+}
+
+func (cfg *apiConfig) handlerStreamChirps(w http.ResponseWriter, r *http.Request) {
+	topic := chirpsTopic
+	authorID := uuid.NullUUID{}
+	if authorParam := r.URL.Query().Get("author_id"); authorParam != "" {
+		parsed, err := uuid.Parse(authorParam)
+		if err != nil {
+			respondWithError(w, 400, "Invalid authorID format")
+			return
+		}
+		authorID = uuid.NullUUID{UUID: parsed, Valid: true}
+		topic = authorTopic(parsed)
+	}
+
+	// Browsers send Last-Event-ID when they reconnect on their own. Other
+	// clients can pass it as a query parameter instead.
+	var lastSent *pageCursor
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		cursor, err := decodeCursor(lastEventID)
+		if err != nil {
+			respondWithError(w, 400, "Invalid Last-Event-ID")
+			return
+		}
+		lastSent = &cursor
+	}
+
+	// Subscribe before replaying so nothing published in between is missed
+	sub := cfg.events.Subscribe(streamBufferSize, topic)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(200)
+	err := rc.Flush()
+	if err != nil {
+		return
+	}
+
+	if lastSent != nil {
+		lastSent, err = cfg.replayChirps(w, r, authorID, *lastSent)
+		if err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				// Dropped for falling behind, or shutting down. The client
+				// reconnects and resumes from its last event ID.
+				return
+			}
+			if msg.ID != "" && lastSent != nil {
+				cursor, err := decodeCursor(msg.ID)
+				if err == nil && !cursorAfter(cursor, *lastSent) {
+					continue
+				}
+			}
+			err = writeServerSentEvent(w, msg)
+			if err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+		}
+		err = rc.Flush()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// replayChirps sends every chirp created after the cursor, oldest first, and
+// returns the cursor of the last chirp sent.
+func (cfg *apiConfig) replayChirps(w http.ResponseWriter, r *http.Request, authorID uuid.NullUUID, cursor pageCursor) (*pageCursor, error) {
+	rc := http.NewResponseController(w)
+	for {
+		cursorCreatedAt, cursorID := cursorParams(&cursor)
+		chirps, err := cfg.dbQueries.ListChirps(r.Context(), database.ListChirpsParams{
+			AuthorID:        authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			PageLimit:       streamReplayLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{}, chirps)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, chirp := range chirps {
+			err = writeServerSentEvent(w, broker.Message{
+				Event: eventChirpCreated,
+				ID:    encodeCursor(chirp.CreatedAt, chirp.ID),
+				Data:  formattedChirps[i],
+			})
+			if err != nil {
+				return nil, err
+			}
+			cursor = pageCursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
+		}
+		err = rc.Flush()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(chirps) < streamReplayLimit {
+			return &cursor, nil
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tiemouie01/chirpy/internal/database"
+)
+
+const purgeInterval = time.Hour
+
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateUser(r)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to restore this chirp.")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Invalid chirp ID")
+		return
+	}
+
+	// Only the author can restore, and only until the grace period ends
+	chirp, err := cfg.dbQueries.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:           id,
+		UserID:       uuid.NullUUID{UUID: userID, Valid: true},
+		GraceSeconds: int32(cfg.chirpRestoreWindow.Seconds()),
+	})
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found or can no longer be restored")
+		return
+	}
+
+	formattedChirps, err := cfg.formatChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Error restoring chirp")
+		return
+	}
+
+	// Listeners dropped the chirp when it was deleted, so it is sent again
+	cfg.publishChirpCreated(r.Context(), chirp)
+
+	respondWithJSON(w, 200, formattedChirps[0])
+}
+
+// runChirpPurge removes chirps deleted longer than the retention period
+// ago, once at startup and then every purgeInterval until ctx is done.
+func (cfg *apiConfig) runChirpPurge(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		err := cfg.purgeDeletedChirps(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error purging deleted chirps: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeDeletedChirps hard-deletes expired chirps. Chirps that still have
+// replies lose their content but stay behind as tombstones so threads keep
+// their shape; they are removed once their replies are gone. Attachments of
+// expired chirps and uploads never attached to a chirp are deleted too.
+func (cfg *apiConfig) purgeDeletedChirps(ctx context.Context) error {
+	retentionSeconds := int32(cfg.chirpRetention.Seconds())
+	var removed []database.Media
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		expired, err := q.DeleteExpiredChirpMedia(ctx, retentionSeconds)
+		if err != nil {
+			return err
+		}
+		abandoned, err := q.DeleteAbandonedMedia(ctx, int32(abandonedMediaAge.Seconds()))
+		if err != nil {
+			return err
+		}
+		removed = append(expired, abandoned...)
+
+		_, err = q.TombstoneExpiredChirps(ctx, retentionSeconds)
+		if err != nil {
+			return err
+		}
+		_, err = q.DeleteTombstonedChirpRevisions(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = q.PurgeExpiredChirps(ctx, retentionSeconds)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	cfg.deleteMediaFiles(ctx, removed)
+	return nil
+}
@@ -19,12 +19,14 @@ type User struct {
 	IsChirpyRed  bool      `json:"is_chirpy_red"`
 	Token        string    `json:"token"`
 	RefreshToken string    `json:"refresh_token"`
+	Username     *string   `json:"username"`
 }
 
 func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request) {
 	type paramters struct {
 		Password string `json:"password"`
 		Email    string `json:"email"`
+		Username string `json:"username"`
 	}
 	decoder := json.NewDecoder(r.Body)
 	params := paramters{}
@@ -34,6 +36,17 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// A username is optional at sign up and can be chosen later
+	username := sql.NullString{}
+	if params.Username != "" {
+		err = validateUsername(params.Username)
+		if err != nil {
+			respondWithError(w, 400, err.Error())
+			return
+		}
+		username = sql.NullString{String: params.Username, Valid: true}
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, 500, "Error hashing user password")
@@ -44,9 +57,14 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 	createUserParams := database.CreateUserParams{
 		Email:          params.Email,
 		HashedPassword: sql.NullString{String: hashedPassword, Valid: true},
+		Username:       username,
 	}
 	user, err := cfg.dbQueries.CreateUser(r.Context(), createUserParams)
 
+	if isUsernameTaken(err) {
+		respondWithError(w, 409, "Username is already taken")
+		return
+	}
 	if err != nil {
 		respondWithError(w, 500, "Error creating user")
 		return
@@ -58,6 +76,7 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		UpdatedAt:   user.UpdatedAt,
 		Email:       user.Email,
 		IsChirpyRed: user.IsChirpyRed.Bool,
+		Username:    nullStringPointer(user.Username),
 	}
 
 	respondWithJSON(w, 201, formattedUser)
@@ -121,6 +140,7 @@ func (cfg *apiConfig) handlerLoginUser(w http.ResponseWriter, r *http.Request) {
 		IsChirpyRed:  user.IsChirpyRed.Bool,
 		Token:        token,
 		RefreshToken: dbToken.Token,
+		Username:     nullStringPointer(user.Username),
 	}
 	respondWithJSON(w, 200, formattedUser)
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/tiemouie01/chirpy/internal/auth"
+	"github.com/tiemouie01/chirpy/internal/database"
+	"github.com/tiemouie01/chirpy/internal/notifications"
 )
 
 func (cfg *apiConfig) handlerUpgradeUser(w http.ResponseWriter, r *http.Request) {
@@ -41,12 +43,30 @@ func (cfg *apiConfig) handlerUpgradeUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Mark the user as a Chirpy Red member
-	err = cfg.dbQueries.UpgradeUser(r.Context(), params.Data.UserID)
+	user, err := cfg.dbQueries.GetUser(r.Context(), params.Data.UserID)
 	if err != nil {
 		respondWithError(w, 404, "User not found")
 		return
 	}
 
+	// Mark the user as a Chirpy Red member
+	var notification *database.Notification
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.UpgradeUser(r.Context(), user.ID)
+		if err != nil || user.IsChirpyRed.Bool {
+			return err
+		}
+		notification, err = notifications.Record(r.Context(), q, notifications.Event{
+			Type:   notifications.TypeChirpyRed,
+			UserID: user.ID,
+		})
+		return err
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error upgrading user")
+		return
+	}
+	cfg.publishNotification(notification)
+
 	w.WriteHeader(204)
 }
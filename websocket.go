@@ -0,0 +1,284 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tiemouie01/chirpy/internal/auth"
+	"github.com/tiemouie01/chirpy/internal/broker"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsReauthGrace    = 30 * time.Second
+	wsSendBufferSize = 64
+	wsMaxMessageSize = 4096
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// Messages sent by the client. Topics are "chirps", "authors/{userID}" and
+// "notifications", which always refers to the connected user's own feed.
+type wsClientMessage struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic"`
+	Token string `json:"token"`
+}
+
+type wsServerMessage struct {
+	Type      string     `json:"type"`
+	Topic     string     `json:"topic,omitempty"`
+	Event     string     `json:"event,omitempty"`
+	ID        string     `json:"id,omitempty"`
+	Data      any        `json:"data,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// wsClient is one realtime connection. Its broker subscription doubles as
+// the connection's send buffer: when the client stops reading and the
+// buffer fills, the broker drops the subscription and the connection is
+// closed. Only writePump writes to the socket.
+type wsClient struct {
+	cfg     *apiConfig
+	conn    *websocket.Conn
+	sub     *broker.Subscription
+	control chan wsServerMessage
+	reauth  chan time.Time
+	done    chan struct{}
+
+	userID uuid.UUID
+	mu     sync.Mutex
+	topics map[string]string
+
+	// Keys of recently written events, oldest first. Only writePump uses them.
+	seen     map[string]struct{}
+	seenKeys []string
+}
+
+func (cfg *apiConfig) handlerWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Browsers cannot set headers on a WebSocket handshake, so the access
+	// token may also be passed as a query parameter.
+	token := r.URL.Query().Get("access_token")
+	if token == "" {
+		headerToken, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, 401, "You are not authorized to access this resource.")
+			return
+		}
+		token = headerToken
+	}
+	userID, expiresAt, err := auth.ValidateJWTWithExpiry(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, 401, "You are not authorized to access this resource.")
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	client := &wsClient{
+		cfg:     cfg,
+		conn:    conn,
+		sub:     cfg.events.Subscribe(wsSendBufferSize),
+		control: make(chan wsServerMessage, 16),
+		reauth:  make(chan time.Time, 1),
+		done:    make(chan struct{}),
+		userID:  userID,
+		topics:  map[string]string{},
+		seen:    map[string]struct{}{},
+	}
+	go client.writePump(expiresAt)
+	client.readPump()
+}
+
+func (c *wsClient) readPump() {
+	defer close(c.done)
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		msg := wsClientMessage{}
+		err := c.conn.ReadJSON(&msg)
+		if err != nil {
+			return
+		}
+
+		reply := c.handleMessage(msg)
+		select {
+		case c.control <- reply:
+		default:
+			// The client is sending faster than it reads its replies
+			return
+		}
+	}
+}
+
+func (c *wsClient) handleMessage(msg wsClientMessage) wsServerMessage {
+	switch msg.Type {
+	case "subscribe":
+		topic, ok := c.brokerTopic(msg.Topic)
+		if !ok {
+			return wsServerMessage{Type: "error", Topic: msg.Topic, Error: "Unknown topic"}
+		}
+		c.mu.Lock()
+		c.topics[topic] = msg.Topic
+		c.mu.Unlock()
+		c.sub.Add(topic)
+		return wsServerMessage{Type: "subscribed", Topic: msg.Topic}
+	case "unsubscribe":
+		topic, ok := c.brokerTopic(msg.Topic)
+		if !ok {
+			return wsServerMessage{Type: "error", Topic: msg.Topic, Error: "Unknown topic"}
+		}
+		c.sub.Remove(topic)
+		c.mu.Lock()
+		delete(c.topics, topic)
+		c.mu.Unlock()
+		return wsServerMessage{Type: "unsubscribed", Topic: msg.Topic}
+	case "authenticate":
+		// A refreshed token must belong to the user who opened the connection
+		userID, expiresAt, err := auth.ValidateJWTWithExpiry(msg.Token, c.cfg.jwtSecret)
+		if err != nil || userID != c.userID {
+			return wsServerMessage{Type: "error", Error: "Invalid access token"}
+		}
+		// Only the newest expiry matters, so replace any that is pending
+		select {
+		case <-c.reauth:
+		default:
+		}
+		c.reauth <- expiresAt
+		return wsServerMessage{Type: "authenticated", ExpiresAt: &expiresAt}
+	default:
+		return wsServerMessage{Type: "error", Error: "Unknown message type"}
+	}
+}
+
+func (c *wsClient) brokerTopic(topic string) (string, bool) {
+	if topic == chirpsTopic {
+		return chirpsTopic, true
+	}
+	if topic == "notifications" {
+		return notificationsTopic(c.userID), true
+	}
+	if authorID, found := strings.CutPrefix(topic, "authors/"); found {
+		parsed, err := uuid.Parse(authorID)
+		if err != nil {
+			return "", false
+		}
+		return authorTopic(parsed), true
+	}
+	return "", false
+}
+
+// writePump delivers subscribed events, control replies and pings. When the
+// access token expires the client is asked to authenticate again and is
+// disconnected if it does not do so within the grace period.
+func (c *wsClient) writePump(expiresAt time.Time) {
+	ping := time.NewTicker(wsPingPeriod)
+	expiry := time.NewTimer(time.Until(expiresAt))
+	var graceExpired <-chan time.Time
+	defer func() {
+		ping.Stop()
+		expiry.Stop()
+		c.sub.Close()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.closeWith(websocket.CloseNormalClosure, "")
+			return
+		case msg, ok := <-c.sub.Messages():
+			if !ok {
+				c.closeWith(websocket.CloseTryAgainLater, "subscriber too slow or server shutting down")
+				return
+			}
+			if c.delivered(msg.Key) {
+				continue
+			}
+			c.mu.Lock()
+			topic := c.topics[msg.Topic]
+			c.mu.Unlock()
+			err := c.write(wsServerMessage{
+				Type:  "event",
+				Topic: topic,
+				Event: msg.Event,
+				ID:    msg.ID,
+				Data:  msg.Data,
+			})
+			if err != nil {
+				return
+			}
+		case reply := <-c.control:
+			err := c.write(reply)
+			if err != nil {
+				return
+			}
+		case expiresAt := <-c.reauth:
+			graceExpired = nil
+			expiry.Reset(time.Until(expiresAt))
+		case <-expiry.C:
+			graceExpired = time.After(wsReauthGrace)
+			err := c.write(wsServerMessage{Type: "reauthenticate"})
+			if err != nil {
+				return
+			}
+		case <-graceExpired:
+			c.closeWith(websocket.ClosePolicyViolation, "access token expired")
+			return
+		case <-ping.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// delivered reports whether an event with this key was already written and
+// records it otherwise. An event published to both "chirps" and its author's
+// topic arrives once per topic. The copies are published back to back, so
+// remembering the last send buffer's worth of keys is enough.
+func (c *wsClient) delivered(key string) bool {
+	if key == "" {
+		return false
+	}
+	if _, ok := c.seen[key]; ok {
+		return true
+	}
+	if len(c.seenKeys) == wsSendBufferSize {
+		delete(c.seen, c.seenKeys[0])
+		c.seenKeys = c.seenKeys[1:]
+	}
+	c.seen[key] = struct{}{}
+	c.seenKeys = append(c.seenKeys, key)
+	return false
+}
+
+func (c *wsClient) write(msg wsServerMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(msg)
+}
+
+func (c *wsClient) closeWith(code int, text string) {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, text))
+}